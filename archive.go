@@ -0,0 +1,174 @@
+package godotenv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+)
+
+// LoadTar loads env files straight out of a tar stream, without unpacking it
+// to disk first. With names it loads those entries in the order given, and it's
+// an error for any of them to be missing from the archive. Without names it
+// loads every entry ending in .env, in the order they appear in the archive.
+//
+// Like Load it WILL NOT OVERRIDE a variable that's already set, so where two
+// entries set the same key the one loaded first wins.
+func LoadTar(archive io.Reader, names ...string) error {
+	contents, err := readTarEntries(archive, names)
+	if err != nil {
+		return err
+	}
+	return loadEntries(contents)
+}
+
+// LoadZip loads env files out of the zip archive at zipPath, without unpacking
+// it to disk first. Entries are picked the same way as for LoadTar, and like
+// Load it WILL NOT OVERRIDE a variable that's already set.
+func LoadZip(zipPath string, names ...string) error {
+	contents, err := readZipEntries(zipPath, names)
+	if err != nil {
+		return err
+	}
+	return loadEntries(contents)
+}
+
+// ReadZip is LoadZip but returns the values in a map rather than setting them,
+// with later entries overriding earlier ones as Read does for files
+func ReadZip(zipPath string, names ...string) (envMap map[string]string, err error) {
+	contents, err := readZipEntries(zipPath, names)
+	if err != nil {
+		return
+	}
+
+	merged := newOrderedEnv()
+	for _, content := range contents {
+		env, err := parse(bytes.NewReader(content), Options{}, merged.values)
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(env)
+	}
+	return merged.unset(), nil
+}
+
+// loadEntries applies each entry in turn, so earlier entries win
+func loadEntries(contents [][]byte) error {
+	loaded := newOrderedEnv()
+	for _, content := range contents {
+		env, err := parse(bytes.NewReader(content), Options{}, loaded.values)
+		if err != nil {
+			return err
+		}
+		env.apply()
+		loaded.merge(env)
+	}
+	return nil
+}
+
+// readZipEntries is readTarEntries for the zip archive at zipPath
+func readZipEntries(zipPath string, names []string) ([][]byte, error) {
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %v: %v", zipPath, err)
+	}
+	defer zipReader.Close()
+
+	entries := make(map[string]*zip.File)
+	var contents [][]byte
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if len(names) > 0 {
+			entries[file.Name] = file
+			continue
+		}
+		if path.Ext(file.Name) == ".env" {
+			content, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+			contents = append(contents, content)
+		}
+	}
+
+	for _, name := range names {
+		file, ok := entries[name]
+		if !ok {
+			return nil, fmt.Errorf("zip entry %q not found", name)
+		}
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	entry, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("reading zip entry %q: %v", file.Name, err)
+	}
+	defer entry.Close()
+
+	content, err := ioutil.ReadAll(entry)
+	if err != nil {
+		return nil, fmt.Errorf("reading zip entry %q: %v", file.Name, err)
+	}
+	return content, nil
+}
+
+// readTarEntries returns the content of the requested entries in the order of
+// names, or of every .env entry in archive order when there are no names
+func readTarEntries(archive io.Reader, names []string) ([][]byte, error) {
+	wanted := make(map[string][]byte)
+	found := make(map[string]bool)
+	for _, name := range names {
+		found[name] = false
+	}
+
+	var contents [][]byte
+	tarReader := tar.NewReader(archive)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		_, isWanted := found[header.Name]
+		if !isWanted && (len(names) > 0 || path.Ext(header.Name) != ".env") {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		if isWanted {
+			wanted[header.Name] = content
+			found[header.Name] = true
+		} else {
+			contents = append(contents, content)
+		}
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("tar entry %q not found", name)
+		}
+		contents = append(contents, wanted[name])
+	}
+	return contents, nil
+}
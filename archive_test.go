@@ -0,0 +1,163 @@
+package godotenv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func buildTar(t *testing.T, files ...string) *bytes.Buffer {
+	buffer := new(bytes.Buffer)
+	tarWriter := tar.NewWriter(buffer)
+	for i := 0; i < len(files); i += 2 {
+		name, content := files[i], files[i+1]
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer
+}
+
+func TestLoadTarAllEnvEntries(t *testing.T) {
+	os.Clearenv()
+	archive := buildTar(t,
+		"config/first.env", "OPTION_A=1\nOPTION_B=first\n",
+		"README", "OPTION_C=not an env file\n",
+		"config/second.env", "OPTION_B=second\nOPTION_D=4\n",
+	)
+
+	if err := LoadTar(archive); err != nil {
+		t.Fatalf("Error loading tar: %v", err)
+	}
+
+	expectedValues := map[string]string{"OPTION_A": "1", "OPTION_B": "first", "OPTION_C": "", "OPTION_D": "4"}
+	for key, value := range expectedValues {
+		if os.Getenv(key) != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, os.Getenv(key))
+		}
+	}
+}
+
+func TestLoadTarNamedEntriesInOrder(t *testing.T) {
+	os.Clearenv()
+	archive := buildTar(t,
+		"first.env", "OPTION_B=first\n",
+		"second.env", "OPTION_B=second\n",
+		"ignored.env", "OPTION_C=3\n",
+	)
+
+	if err := LoadTar(archive, "second.env", "first.env"); err != nil {
+		t.Fatalf("Error loading tar: %v", err)
+	}
+	if os.Getenv("OPTION_B") != "second" {
+		t.Errorf("Expected the first named entry to win, got '%v'", os.Getenv("OPTION_B"))
+	}
+	if os.Getenv("OPTION_C") != "" {
+		t.Error("Loaded an entry that wasn't named")
+	}
+}
+
+func TestLoadTarMissingEntry(t *testing.T) {
+	archive := buildTar(t, "first.env", "OPTION_A=1\n")
+
+	err := LoadTar(archive, "first.env", "missing.env")
+	if err == nil || err.Error() != `tar entry "missing.env" not found` {
+		t.Errorf("Expected a missing entry error, got %v", err)
+	}
+}
+
+// buildZip writes a zip of name, content pairs to a temp file, the caller
+// removes it
+func buildZip(t *testing.T, files ...string) string {
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	for i := 0; i < len(files); i += 2 {
+		entry, err := zipWriter.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestLoadZipAllEnvEntries(t *testing.T) {
+	os.Clearenv()
+	zipPath := buildZip(t,
+		"config/first.env", "OPTION_A=1\nOPTION_B=first\n",
+		"README", "OPTION_C=not an env file\n",
+		"config/second.env", "OPTION_B=second\nOPTION_D=4\n",
+	)
+	defer os.Remove(zipPath)
+
+	if err := LoadZip(zipPath); err != nil {
+		t.Fatalf("Error loading zip: %v", err)
+	}
+
+	expectedValues := map[string]string{"OPTION_A": "1", "OPTION_B": "first", "OPTION_C": "", "OPTION_D": "4"}
+	for key, value := range expectedValues {
+		if os.Getenv(key) != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, os.Getenv(key))
+		}
+	}
+}
+
+func TestReadZipNamedEntriesInOrder(t *testing.T) {
+	os.Clearenv()
+	zipPath := buildZip(t,
+		"first.env", "OPTION_B=first\n",
+		"second.env", "OPTION_B=second\nOPTION_A=$OPTION_B\n",
+		"ignored.env", "OPTION_C=3\n",
+	)
+	defer os.Remove(zipPath)
+
+	envMap, err := ReadZip(zipPath, "first.env", "second.env")
+	if err != nil {
+		t.Fatalf("Error reading zip: %v", err)
+	}
+	if envMap["OPTION_B"] != "second" || envMap["OPTION_A"] != "second" {
+		t.Errorf("Expected the later entry to win, got %v", envMap)
+	}
+	if _, ok := envMap["OPTION_C"]; ok {
+		t.Error("Read an entry that wasn't named")
+	}
+	if os.Getenv("OPTION_B") != "" {
+		t.Error("ReadZip shouldn't set anything in the env")
+	}
+}
+
+func TestLoadZipErrors(t *testing.T) {
+	zipPath := buildZip(t, "first.env", "OPTION_A=1\n")
+	defer os.Remove(zipPath)
+
+	err := LoadZip(zipPath, "first.env", "missing.env")
+	if err == nil || err.Error() != `zip entry "missing.env" not found` {
+		t.Errorf("Expected a missing entry error, got %v", err)
+	}
+
+	err = LoadZip("fixtures/plain.env")
+	if err == nil || !strings.HasPrefix(err.Error(), "opening fixtures/plain.env: ") {
+		t.Errorf("Expected a corrupt archive error, got %v", err)
+	}
+}
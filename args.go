@@ -0,0 +1,27 @@
+package godotenv
+
+import "strings"
+
+// ParseArgs picks the KEY=value assignments out of command line arguments,
+// like the trailing ones to docker run -e, and parses their values with the
+// same rules as a file, quotes, escapes, references and all. A reference can
+// use an earlier argument. It's for layering overrides from the command line
+// over file config.
+//
+// Everything else is skipped: flags (anything starting with -), arguments
+// without an =, and those whose key isn't a portable variable name, so a URL
+// with a query string isn't mistaken for an assignment.
+func ParseArgs(args []string) map[string]string {
+	envMap := make(map[string]string)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || !strings.Contains(arg, "=") {
+			continue
+		}
+		key, value, err := parseLine(arg, envMap, Options{})
+		if err != nil || !isPosixKey(key) {
+			continue
+		}
+		envMap[key] = value
+	}
+	return envMap
+}
@@ -0,0 +1,44 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOME", "/home/me")
+
+	envMap := ParseArgs([]string{
+		"-e", "PORT=8080",
+		"--verbose",
+		"--name=web",
+		"HOST=example.com",
+		`GREETING="hello world\n"`,
+		"LITERAL='$HOME'",
+		"URL=http://${HOST}:${PORT}",
+		"CACHE=$HOME/.cache",
+		"EMPTY=",
+		"image:latest",
+		"http://example.com/?a=b",
+		"run",
+	})
+
+	expectedValues := map[string]string{
+		"PORT":     "8080",
+		"HOST":     "example.com",
+		"GREETING": "hello world\n",
+		"LITERAL":  "$HOME",
+		"URL":      "http://example.com:8080",
+		"CACHE":    "/home/me/.cache",
+		"EMPTY":    "",
+	}
+	if len(envMap) != len(expectedValues) {
+		t.Errorf("Expected %d assignments got %v", len(expectedValues), envMap)
+	}
+	for key, value := range expectedValues {
+		if actual, ok := envMap[key]; !ok || actual != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, actual)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package godotenv
+
+// LoadAsync is Load run in a goroutine, so loading config can overlap with
+// other startup work. The result, nil or the error, is sent on the returned
+// channel once the load finishes, and the channel is then closed. Setting the
+// environment is still a side effect of the load, so receive from the channel
+// before relying on any of the variables.
+func LoadAsync(filenames ...string) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		done <- Load(filenames...)
+	}()
+	return done
+}
@@ -0,0 +1,31 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadAsync(t *testing.T) {
+	os.Clearenv()
+	done := LoadAsync("fixtures/plain.env")
+
+	if err := <-done; err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	if os.Getenv("OPTION_A") != "1" {
+		t.Errorf("Expected the file to be loaded once the result arrives, got '%v'", os.Getenv("OPTION_A"))
+	}
+	if err, ok := <-done; ok {
+		t.Errorf("Expected exactly one result, got a second: %v", err)
+	}
+}
+
+func TestLoadAsyncError(t *testing.T) {
+	done := LoadAsync("somefilethatwillneverexistever.env")
+	if err := <-done; !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+	if _, ok := <-done; ok {
+		t.Error("Expected the channel to be closed after the result")
+	}
+}
@@ -0,0 +1,67 @@
+package godotenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadBetween reads only the lines of filename between a line that is
+// beginMarker and a line that is endMarker, so env vars can live inside a
+// bigger file like a deployment manifest:
+//
+//	# BEGIN ENV
+//	PORT=8080
+//	# END ENV
+//
+// Markers match the whole line, ignoring surrounding whitespace, and only the
+// first region is read. A file without beginMarker gives an empty map, but
+// one with beginMarker and no endMarker after it is an error. The region is
+// parsed with the standard rules, as Read would, and errors give line numbers
+// in the whole file.
+func ReadBetween(filename, beginMarker, endMarker string) (envMap map[string]string, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, defaultMaxLineBytes)
+	scanner.Split(scanLines)
+
+	// lines outside the region are blanked rather than dropped, to keep the
+	// line numbers right
+	var region bytes.Buffer
+	var inside, found, closed bool
+	for !closed && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case !found && strings.TrimSpace(line) == beginMarker:
+			found, inside = true, true
+		case inside && strings.TrimSpace(line) == endMarker:
+			inside, closed = false, true
+		case inside:
+			region.WriteString(line)
+		}
+		region.WriteByte('\n')
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if inside {
+		err = fmt.Errorf("%v: found %q but no %q after it", filename, beginMarker, endMarker)
+		return
+	}
+
+	env, err := parse(&region, Options{baseDir: filepath.Dir(filename)}, nil)
+	if err != nil {
+		err = fmt.Errorf("parsing %q: %w", filename, err)
+		return
+	}
+	envMap = env.unset()
+	return
+}
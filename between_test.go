@@ -0,0 +1,41 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadBetween(t *testing.T) {
+	os.Clearenv()
+	envMap, err := ReadBetween("fixtures/manifest.yml", "# BEGIN ENV", "# END ENV")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"PORT": "8080",
+		"HOST": "example.com",
+		"URL":  "http://example.com:8080",
+	}
+	if len(envMap) != len(expectedValues) {
+		t.Errorf("Expected only the enclosed keys, got %v", envMap)
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+
+	envMap, err = ReadBetween("fixtures/manifest.yml", "# BEGIN CONFIG", "# END CONFIG")
+	if err != nil || len(envMap) != 0 {
+		t.Errorf("Expected nothing without the begin marker, got %v and %v", envMap, err)
+	}
+}
+
+func TestReadBetweenMissingEndMarker(t *testing.T) {
+	_, err := ReadBetween("fixtures/unclosedmanifest.yml", "# BEGIN ENV", "# END ENV")
+	if err == nil || !strings.Contains(err.Error(), `no "# END ENV"`) {
+		t.Errorf("Expected a missing end marker error, got %v", err)
+	}
+}
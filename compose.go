@@ -0,0 +1,89 @@
+package godotenv
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ValidateComposeCompat reads an env file from r and reports what this
+// package accepts but Docker Compose's env_file would reject or read
+// differently, so one file can serve both. Nothing about parsing changes,
+// these are only warnings. The rules checked are
+//
+//   - [section] headers and YAML block scalars (KEY: |), which Compose
+//     doesn't support (errors)
+//   - KEY: value, where Compose needs KEY=value (error)
+//   - \= and \: escaped in keys, which Compose keeps as they are (error)
+//   - export before a key, which older Compose versions don't strip
+//   - \' in a single quoted value, Compose takes single quotes literally so
+//     it doesn't escape anything
+//   - \$ in a double quoted value, Compose escapes a dollar as $$
+//   - # right after an unquoted value, which starts a comment here but is part
+//     of the value to Compose unless there's whitespace before it
+//
+// The error is only for failing to read r.
+func ValidateComposeCompat(r io.Reader) (warnings []Warning, err error) {
+	warn := func(line int, severity Severity, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	checkSection := func(lineNumber int, line string) {
+		if _, isHeader := parseSectionHeader(line); isHeader {
+			warn(lineNumber, SeverityError, "Compose doesn't support [section] headers")
+		}
+	}
+
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return
+	}
+	p.collectErrors = true
+	p.onSkip = checkSection
+	p.onAssign = func(a assignment) {
+		lineNumber, fullLine := a.first, a.text
+		if key, _, isBlock := parseBlockScalarHeader(fullLine); isBlock {
+			warn(lineNumber, SeverityError, "Compose doesn't support YAML block scalars, use a quoted value for %v", key)
+			return
+		}
+
+		rawKey, rawValue, _ := splitKeyValue(fullLine)
+		key := strings.Trim(rawKey, " \t")
+		if fullLine[len(rawKey)] == ':' {
+			warn(lineNumber, SeverityError, "Compose needs KEY=value, not %v: value", key)
+		}
+		if strings.HasPrefix(key, "export ") || strings.HasPrefix(key, "export\t") {
+			key = strings.Trim(strings.TrimPrefix(key, "export"), " \t")
+			warn(lineNumber, SeverityWarning, "older Compose versions don't strip export from %v", key)
+		}
+		if strings.Contains(key, `\=`) || strings.Contains(key, `\:`) {
+			warn(lineNumber, SeverityError, "Compose keeps the backslashes in key %v", key)
+		}
+
+		rawValue = strings.Trim(rawValue, " \t")
+		switch {
+		case strings.HasPrefix(rawValue, "'"):
+			if strings.Contains(rawValue, `\'`) {
+				warn(lineNumber, SeverityWarning, `Compose takes single quoted values literally, \' doesn't escape a quote in %v`, key)
+			}
+		case strings.HasPrefix(rawValue, `"`):
+			if strings.Contains(rawValue, `\$`) {
+				warn(lineNumber, SeverityWarning, `Compose escapes $ as $$, not \$, in %v`, key)
+			}
+		default:
+			if hash := strings.Index(rawValue, "#"); hash > 0 && !strings.ContainsAny(rawValue[hash-1:hash], " \t") {
+				warn(lineNumber, SeverityWarning, "the # in %v starts a comment here but is part of the value to Compose", key)
+			}
+		}
+	}
+	if err = p.parse(r); err != nil {
+		return nil, err
+	}
+	for _, lineError := range p.lineErrors {
+		checkSection(lineError.Line, lineError.Content)
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	return
+}
@@ -0,0 +1,38 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidateComposeCompat(t *testing.T) {
+	file, err := os.Open("fixtures/composecompat.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	warnings, err := ValidateComposeCompat(file)
+	if err != nil {
+		t.Fatalf("Error validating: %v", err)
+	}
+
+	expected := []Warning{
+		{Line: 9, Severity: SeverityError, Message: "Compose doesn't support [section] headers"},
+		{Line: 10, Severity: SeverityError, Message: "Compose needs KEY=value, not COLON: value"},
+		{Line: 11, Severity: SeverityWarning, Message: "older Compose versions don't strip export from EXPORTED"},
+		{Line: 12, Severity: SeverityError, Message: `Compose keeps the backslashes in key ESCAPED\=KEY`},
+		{Line: 13, Severity: SeverityWarning, Message: `Compose takes single quoted values literally, \' doesn't escape a quote in SINGLE`},
+		{Line: 14, Severity: SeverityWarning, Message: `Compose escapes $ as $$, not \$, in DOLLAR`},
+		{Line: 15, Severity: SeverityWarning, Message: "the # in HASH starts a comment here but is part of the value to Compose"},
+		{Line: 16, Severity: SeverityError, Message: "Compose doesn't support YAML block scalars, use a quoted value for BLOCK"},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i, warning := range warnings {
+		if warning != expected[i] {
+			t.Errorf("Expected '%v' got '%v'", expected[i], warning)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package godotenv
+
+import "sort"
+
+// Config is a read only snapshot of config values. Nothing can change it once
+// it's made, so any number of goroutines can read from it at once without
+// locking. Being a snapshot it doesn't see later changes to the files or to the
+// environment, os.Setenv included. With derives a new Config with a value
+// overridden, for things like per request overrides in a server where
+// os.Setenv would leak between requests, and Environ hands one to os/exec.
+type Config struct {
+	values map[string]string
+}
+
+// NewConfig makes a Config from a copy of envMap, so changing envMap later
+// doesn't affect it
+func NewConfig(envMap map[string]string) *Config {
+	values := make(map[string]string, len(envMap))
+	for key, value := range envMap {
+		values[key] = value
+	}
+	return &Config{values: values}
+}
+
+// ReadConfig reads the files (or .env) into a Config. Unlike Read the values are
+// exactly what the files say, whether or not the environment has them set.
+func ReadConfig(filenames ...string) (*Config, error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &Config{values: env.values}, nil
+}
+
+// Get returns the value for key, and whether it was there at all
+func (c *Config) Get(key string) (string, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// Keys returns every key in the Config, sorted
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// With returns a copy of c with key set to value. c itself is unchanged.
+func (c *Config) With(key, value string) *Config {
+	values := make(map[string]string, len(c.values)+1)
+	for k, v := range c.values {
+		values[k] = v
+	}
+	values[key] = value
+	return &Config{values: values}
+}
+
+// Environ returns the values as KEY=value strings, sorted by key, in the form
+// os/exec.Cmd's Env wants
+func (c *Config) Environ() []string {
+	keys := c.Keys()
+	environ := make([]string, 0, len(keys))
+	for _, key := range keys {
+		environ = append(environ, key+"="+c.values[key])
+	}
+	return environ
+}
@@ -0,0 +1,105 @@
+package godotenv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "actualenv")
+
+	config, err := ReadConfig("fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Error reading config: %v", err)
+	}
+
+	if value, ok := config.Get("OPTION_A"); !ok || value != "1" {
+		t.Errorf("Expected the file's OPTION_A, got '%v' %v", value, ok)
+	}
+	if _, ok := config.Get("MISSING"); ok {
+		t.Error("Expected a missing key to be reported missing")
+	}
+	if keys := strings.Join(config.Keys(), ","); keys != "OPTION_A,OPTION_B,OPTION_C,OPTION_D,OPTION_E" {
+		t.Errorf("Expected sorted keys, got %v", keys)
+	}
+
+	os.Setenv("OPTION_B", "changed")
+	if value, _ := config.Get("OPTION_B"); value != "2" {
+		t.Errorf("Expected the config to be a snapshot, got '%v'", value)
+	}
+}
+
+func TestNewConfigCopies(t *testing.T) {
+	envMap := map[string]string{"OPTION_A": "1"}
+	config := NewConfig(envMap)
+	envMap["OPTION_A"] = "changed"
+
+	if value, _ := config.Get("OPTION_A"); value != "1" {
+		t.Errorf("Expected the config to keep its own copy, got '%v'", value)
+	}
+}
+
+func TestConfigConcurrentReads(t *testing.T) {
+	config := NewConfig(map[string]string{"OPTION_A": "1"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if value, _ := config.Get("OPTION_A"); value != "1" {
+					t.Errorf("Expected '1', got '%v'", value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigWith(t *testing.T) {
+	envMap := map[string]string{"HOST": "localhost", "PORT": "8080"}
+	base := NewConfig(envMap)
+	envMap["PORT"] = "changed"
+
+	if port, _ := base.Get("PORT"); port != "8080" {
+		t.Errorf("Expected changing the map not to affect the Config, got %v", port)
+	}
+
+	override := base.With("PORT", "9090").With("DEBUG", "true")
+	if port, _ := override.Get("PORT"); port != "9090" {
+		t.Errorf("Expected the override, got %v", port)
+	}
+	if port, _ := base.Get("PORT"); port != "8080" {
+		t.Errorf("Expected With to leave the base alone, got %v", port)
+	}
+	if _, ok := base.Get("DEBUG"); ok {
+		t.Error("Expected With to leave the base alone")
+	}
+
+	expected := []string{"DEBUG=true", "HOST=localhost", "PORT=9090"}
+	if !reflect.DeepEqual(override.Environ(), expected) {
+		t.Errorf("Expected %v got %v", expected, override.Environ())
+	}
+
+	if os.Getenv("DEBUG") == "true" {
+		t.Error("Config shouldn't touch the process environment")
+	}
+}
+
+func TestConfigZeroValue(t *testing.T) {
+	var config Config
+	if _, ok := config.Get("ANYTHING"); ok {
+		t.Error("Expected an empty Config to have nothing in it")
+	}
+	if value, _ := config.With("KEY", "value").Get("KEY"); value != "value" {
+		t.Errorf("Expected With to work on an empty Config, got %v", value)
+	}
+	if len(config.Environ()) != 0 {
+		t.Errorf("Expected no environ, got %v", config.Environ())
+	}
+}
@@ -0,0 +1,77 @@
+package godotenv
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Format is the flavour of env file DetectFormat thinks it's looking at
+type Format int
+
+const (
+	// FormatUnknown is for empty content, or a mix of styles
+	FormatUnknown Format = iota
+	// FormatDotenv is plain KEY=value lines, possibly with [section] headers
+	FormatDotenv
+	// FormatYAML is YAML(ish) KEY: value lines
+	FormatYAML
+	// FormatShell is a sourceable script of export KEY=value lines
+	FormatShell
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatDotenv:
+		return "dotenv"
+	case FormatYAML:
+		return "yaml"
+	case FormatShell:
+		return "shell"
+	}
+	return "unknown"
+}
+
+// DetectFormat looks over env content and guesses what style it's written in,
+// to help decide how to treat it before parsing. Content that mixes styles, or
+// has no assignments at all, is FormatUnknown. It reads r to the end, so buffer
+// the content first if you want to parse it afterwards.
+func DetectFormat(r io.Reader) (Format, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return FormatUnknown, err
+	}
+
+	var dotenvLines, yamlLines, shellLines int
+	for _, line := range splitLines(decodeUTF16(content)) {
+		if isIgnoredLine(line) {
+			continue
+		}
+		if _, isHeader := parseSectionHeader(line); isHeader {
+			dotenvLines++
+			continue
+		}
+
+		trimmedLine := strings.TrimLeft(line, " \t")
+		equals := strings.Index(trimmedLine, "=")
+		colon := strings.Index(trimmedLine, ":")
+		switch {
+		case strings.HasPrefix(trimmedLine, "export ") && equals > 0:
+			shellLines++
+		case equals > 0 && (colon < 0 || equals < colon):
+			dotenvLines++
+		case colon > 0:
+			yamlLines++
+		}
+	}
+
+	switch {
+	case shellLines > 0 && dotenvLines == 0 && yamlLines == 0:
+		return FormatShell, nil
+	case yamlLines > 0 && dotenvLines == 0 && shellLines == 0:
+		return FormatYAML, nil
+	case dotenvLines > 0 && yamlLines == 0 && shellLines == 0:
+		return FormatDotenv, nil
+	}
+	return FormatUnknown, nil
+}
@@ -0,0 +1,52 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	contents := map[string]Format{
+		"OPTION_A=1\nOPTION_B=http://example.com\n":   FormatDotenv,
+		"# comment\n[production]\nOPTION_A=1\n":       FormatDotenv,
+		"OPTION_A: 1\nOPTION_B: http://example.com\n": FormatYAML,
+		"export OPTION_A=1\n  export OPTION_B='2'\n":  FormatShell,
+		"OPTION_A=1\nOPTION_B: 2\n":                   FormatUnknown,
+		"OPTION_A=1\rOPTION_B: 2\r":                   FormatUnknown,
+		"OPTION_A: 1\r\nOPTION_B: 2\r\n":              FormatYAML,
+		"export OPTION_A=1\nOPTION_B=2\n":             FormatUnknown,
+		"# just a comment\n\n":                        FormatUnknown,
+		"":                                            FormatUnknown,
+	}
+
+	for content, expected := range contents {
+		format, err := DetectFormat(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("Error detecting format: %v", err)
+		}
+		if format != expected {
+			t.Errorf("Expected %q to be %v, got %v", content, expected, format)
+		}
+	}
+}
+
+func TestDetectFormatFixtures(t *testing.T) {
+	fixtures := map[string]Format{
+		"fixtures/plain.env":    FormatDotenv,
+		"fixtures/exported.env": FormatShell,
+		"fixtures/sections.env": FormatDotenv,
+	}
+
+	for filename, expected := range fixtures {
+		file, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format, err := DetectFormat(file)
+		file.Close()
+		if err != nil || format != expected {
+			t.Errorf("Expected %v to be %v, got %v %v", filename, expected, format, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package godotenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadFromEnvVar loads a whole env file passed base64 encoded in the variable
+// varName (e.g. DOTENV_B64), for CI systems that can only pass secrets as single
+// values. The content must be standard base64 with padding, as produced by
+// `base64 < .env`, line breaks in it are ignored. Like Load it WILL NOT
+// OVERRIDE variables that are already set.
+func LoadFromEnvVar(varName string) error {
+	encoded, ok := os.LookupEnv(varName)
+	if !ok {
+		return fmt.Errorf("%v is not set", varName)
+	}
+
+	// base64 tools like to wrap their output
+	encoded = strings.NewReplacer("\n", "", "\r", "").Replace(encoded)
+	content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return fmt.Errorf("decoding %v: %v", varName, err)
+	}
+
+	env, err := parse(bytes.NewReader(content), Options{}, nil)
+	if err != nil {
+		return fmt.Errorf("parsing %v: %v", varName, err)
+	}
+
+	env.apply()
+	return nil
+}
@@ -0,0 +1,44 @@
+package godotenv
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromEnvVar(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_B", "actualenv")
+	os.Setenv("DOTENV_B64", base64.StdEncoding.EncodeToString([]byte("OPTION_A=1\nOPTION_B=2\nOPTION_C=\"three\"\n")))
+
+	if err := LoadFromEnvVar("DOTENV_B64"); err != nil {
+		t.Fatalf("Error loading from env var: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"OPTION_A": "1",
+		"OPTION_B": "actualenv",
+		"OPTION_C": "three",
+	}
+	for key, value := range expectedValues {
+		if os.Getenv(key) != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, os.Getenv(key))
+		}
+	}
+}
+
+func TestLoadFromEnvVarErrors(t *testing.T) {
+	os.Clearenv()
+
+	err := LoadFromEnvVar("DOTENV_B64")
+	if err == nil || !strings.Contains(err.Error(), "DOTENV_B64 is not set") {
+		t.Errorf("Expected an unset var to be an error, got %v", err)
+	}
+
+	os.Setenv("DOTENV_B64", "not base64!")
+	err = LoadFromEnvVar("DOTENV_B64")
+	if err == nil || !strings.Contains(err.Error(), "decoding DOTENV_B64") {
+		t.Errorf("Expected bad base64 to be an error, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package godotenv
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// escape sequences understood inside double quotes
+var doubleQuoteEscapes = map[byte]string{
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'"':  "\"",
+	'\\': "\\",
+}
+
+// a $VAR or ${VAR...} found in a value
+type variableReference struct {
+	name string
+	// operator is one of the POSIX substitution forms :- - :? ? or empty
+	operator string
+	// word is the default value or error message following the operator
+	word string
+}
+
+// expandVariables replaces $VAR and ${VAR} references in value, looking first
+// at the values already parsed from the same file and then at the environment
+// (see lookupVariable).
+// A reference to a variable that is set nowhere expands to an empty string, and
+// \$ gives a literal dollar sign. When unescape is set the double quote escapes
+// (\n, \t and friends) are processed in the same pass so that an expanded value
+// never has its backslashes reinterpreted.
+//
+// The POSIX substitution forms are supported too:
+//
+//	${VAR:-default}  default if VAR is unset or empty
+//	${VAR-default}   default if VAR is unset
+//	${VAR:?message}  error with message if VAR is unset or empty
+//	${VAR?message}   error with message if VAR is unset
+//
+// ExpansionDelims swaps ${ and } for other delimiters, turning off bare $VAR
+// and \$ (a backslash before the opening delimiter makes it literal instead).
+//
+// With ExpandPercentStyle, Windows style %VAR% references are expanded too,
+// though unlike $VAR a %VAR% that isn't set anywhere is left as it is.
+func expandVariables(value string, envMap map[string]string, unescape bool, opts Options) (string, error) {
+	var expanded strings.Builder
+	delims := opts.ExpansionDelims
+
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+
+		if char == '\\' && i+1 < len(value) {
+			next := value[i+1]
+			if delims.Open != "" && strings.HasPrefix(value[i+1:], delims.Open) {
+				expanded.WriteString(delims.Open)
+				i += len(delims.Open)
+				continue
+			}
+			if next == '$' && delims.Open == "" {
+				expanded.WriteByte('$')
+				i++
+				continue
+			}
+			if replacement, ok := doubleQuoteEscapes[next]; ok && unescape {
+				expanded.WriteString(replacement)
+				i++
+				continue
+			}
+		}
+
+		if delims.Open != "" && strings.HasPrefix(value[i:], delims.Open) {
+			reference, length := scanDelimitedReference(value[i:], delims)
+			if length > 0 {
+				substitution, err := substituteVariable(reference, envMap, opts)
+				if err != nil {
+					return "", err
+				}
+				expanded.WriteString(substitution)
+				i += length - 1
+				continue
+			}
+		}
+
+		if char == '$' && delims.Open == "" {
+			reference, length := scanVariableReference(value[i+1:])
+			if length > 0 {
+				substitution, err := substituteVariable(reference, envMap, opts)
+				if err != nil {
+					return "", err
+				}
+				expanded.WriteString(substitution)
+				i += length
+				continue
+			}
+		}
+
+		if char == '%' && opts.ExpandPercentStyle {
+			if end := strings.IndexByte(value[i+1:], '%'); end > 0 && isPosixKey(value[i+1:i+1+end]) {
+				if substitution, ok := lookupVariable(value[i+1:i+1+end], envMap, opts); ok {
+					expanded.WriteString(substitution)
+					i += end + 1
+					continue
+				}
+			}
+		}
+
+		expanded.WriteByte(char)
+	}
+
+	return expanded.String(), nil
+}
+
+// scanVariableReference reads the reference from the text following a $, either
+// wrapped in braces or bare, and reports how many bytes it used. A length of
+// zero means there's no reference and the $ is literal.
+func scanVariableReference(text string) (reference variableReference, length int) {
+	if !strings.HasPrefix(text, "{") {
+		if len(text) == 0 || text[0] >= '0' && text[0] <= '9' {
+			return
+		}
+		for length < len(text) && isVariableChar(rune(text[length])) {
+			length++
+		}
+		reference.name = text[:length]
+		return
+	}
+
+	end := matchingBrace(text)
+	if end < 0 {
+		return
+	}
+	if reference, ok := parseReferenceBody(text[1:end]); ok {
+		return reference, end + 1
+	}
+	return
+}
+
+// matchingBrace finds the } closing the { that text starts with, skipping over
+// nested pairs like those of ${B:-${C}}, or -1 if it's never closed. A
+// backslash stops the brace after it counting.
+func matchingBrace(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// scanDelimitedReference is scanVariableReference for custom delimiters, text
+// starts with delims.Open and the length includes both delimiters
+func scanDelimitedReference(text string, delims Delims) (reference variableReference, length int) {
+	if delims.Close == "" {
+		return
+	}
+	end := strings.Index(text[len(delims.Open):], delims.Close)
+	if end < 0 {
+		return
+	}
+	if reference, ok := parseReferenceBody(text[len(delims.Open) : len(delims.Open)+end]); ok {
+		return reference, len(delims.Open) + end + len(delims.Close)
+	}
+	return
+}
+
+// parseReferenceBody parses what's between the braces of a reference, a name
+// optionally followed by one of the substitution forms
+func parseReferenceBody(body string) (reference variableReference, ok bool) {
+	nameLength := strings.IndexFunc(body, func(char rune) bool { return !isVariableChar(char) && char != '.' })
+	if nameLength < 0 {
+		nameLength = len(body)
+	}
+	reference.name = body[:nameLength]
+	if len(reference.name) == 0 || reference.name[0] >= '0' && reference.name[0] <= '9' {
+		return variableReference{}, false
+	}
+
+	rest := body[nameLength:]
+	for _, operator := range []string{":-", ":?", "-", "?"} {
+		if strings.HasPrefix(rest, operator) {
+			reference.operator = operator
+			reference.word = rest[len(operator):]
+			return reference, true
+		}
+	}
+	if len(rest) > 0 {
+		return variableReference{}, false
+	}
+
+	return reference, true
+}
+
+func substituteVariable(reference variableReference, envMap map[string]string, opts Options) (string, error) {
+	value, isSet := lookupVariable(reference.name, envMap, opts)
+
+	switch reference.operator {
+	case ":-", "-":
+		if !isSet || reference.operator == ":-" && value == "" {
+			return expandVariables(reference.word, envMap, false, opts)
+		}
+	case ":?", "?":
+		if !isSet || reference.operator == ":?" && value == "" {
+			message := reference.word
+			if message == "" {
+				message = "parameter not set"
+			}
+			return "", fmt.Errorf("%v: %v", reference.name, message)
+		}
+	}
+
+	return value, nil
+}
+
+func isVariableChar(char rune) bool {
+	return char == '_' || char >= 'A' && char <= 'Z' || char >= 'a' && char <= 'z' || char >= '0' && char <= '9'
+}
+
+// BuiltinExpandFunctions are the pseudo-variables for Options.ExpandFunctions.
+// __DATE__ and __TIME__ are the current UTC date (2006-01-02) and time
+// (15:04:05), and __UUID__ a new random (version 4) UUID every time.
+var BuiltinExpandFunctions = map[string]func() string{
+	"__DATE__": func() string { return time.Now().UTC().Format("2006-01-02") },
+	"__TIME__": func() string { return time.Now().UTC().Format("15:04:05") },
+	"__UUID__": newUUID,
+}
+
+func isPseudoVariable(name string) bool {
+	return len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__")
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// lookupVariable finds a referenced variable among ExpandFunctions and the
+// values parsed so far, falling back to the environment (limited to
+// ExpansionAllowPrefix if it's set) unless ExpandFromFilesOnly is set, and
+// then to the environment under ExpansionEnvPrefix
+func lookupVariable(name string, envMap map[string]string, opts Options) (string, bool) {
+	if opts.lookup != nil {
+		if value, ok := opts.lookup(name); ok {
+			return value, true
+		}
+	}
+	if fn, ok := opts.ExpandFunctions[name]; ok && isPseudoVariable(name) {
+		return fn(), true
+	}
+	if value, ok := envMap[name]; ok {
+		return value, true
+	}
+	if opts.ExpandFromFilesOnly || !strings.HasPrefix(name, opts.ExpansionAllowPrefix) {
+		return "", false
+	}
+	if value, ok := os.LookupEnv(name); ok || opts.ExpansionEnvPrefix == "" {
+		return value, ok
+	}
+	return os.LookupEnv(opts.ExpansionEnvPrefix + name)
+}
@@ -0,0 +1,386 @@
+package godotenv
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSubstitutions(t *testing.T) {
+	envFileName := "fixtures/substitutions.env"
+	expectedValues := map[string]string{
+		"OPTION_A": "1",
+		"OPTION_B": "1",
+		"OPTION_C": "1",
+		"OPTION_D": "$OPTION_A\\n",
+		"OPTION_E": "1\n",
+		"OPTION_F": "$OPTION_A",
+		"OPTION_G": "",
+		"OPTION_H": "1_1",
+	}
+
+	loadEnvAndCompareValues(t, envFileName, expectedValues)
+}
+
+func TestSingleQuotesAreLiteral(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO", "foo")
+
+	parseAndCompare(t, `BAR='$FOO\n'`, "BAR", `$FOO\n`)
+	parseAndCompare(t, `BAR='${FOO}\t\\'`, "BAR", `${FOO}\t\\`)
+	parseAndCompare(t, `BAR="$FOO\n"`, "BAR", "foo\n")
+	parseAndCompare(t, `BAR="${FOO}\t\\"`, "BAR", "foo\t\\")
+}
+
+func TestExpandVariablesFromEnvironment(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO", "from env")
+
+	// same file values are preferred to the environment
+	envMap := map[string]string{"BAR": "from file"}
+	if value, _ := expandVariables("$FOO/${BAR}", envMap, false, Options{}); value != "from env/from file" {
+		t.Errorf("Expected 'from env/from file', got '%v'", value)
+	}
+
+	// a lone dollar or unterminated brace is left alone
+	if value, _ := expandVariables("costs $5 or ${FOO", envMap, false, Options{}); value != "costs $5 or ${FOO" {
+		t.Errorf("Expected literal dollars to be left alone, got '%v'", value)
+	}
+}
+
+func TestSubstitutionForms(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SET", "set")
+	os.Setenv("EMPTY", "")
+
+	parseAndCompare(t, "FOO=${SET:-default}", "FOO", "set")
+	parseAndCompare(t, "FOO=${EMPTY:-default}", "FOO", "default")
+	parseAndCompare(t, "FOO=${UNSET:-default}", "FOO", "default")
+	parseAndCompare(t, "FOO=${EMPTY-default}", "FOO", "")
+	parseAndCompare(t, "FOO=${UNSET-default}", "FOO", "default")
+	parseAndCompare(t, "FOO=\"${UNSET:-$SET and more}\"", "FOO", "set and more")
+	parseAndCompare(t, "FOO=${SET:?required}", "FOO", "set")
+	parseAndCompare(t, "FOO=${EMPTY?required}", "FOO", "")
+}
+
+func TestNestedSubstitutions(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("SET", "set")
+
+	// two levels
+	parseAndCompare(t, "A=${B:-${C:-fallback}}", "A", "fallback")
+	parseAndCompare(t, "A=${B:-${SET:-fallback}}", "A", "set")
+	parseAndCompare(t, "A=${B:-${C:-fallback}} and after", "A", "fallback and after")
+
+	// three levels
+	parseAndCompare(t, "A=${B:-${C:-${D:-deepest}}}", "A", "deepest")
+	parseAndCompare(t, "A=${B:-${C:-${SET}}}/suffix", "A", "set/suffix")
+	parseAndCompare(t, "A=\"${B:-x${C:-${D:-y}}z}\"", "A", "xyz")
+
+	// braces that aren't references still pair up, and a stray } is literal
+	parseAndCompare(t, "A=${B:-{json}}", "A", "{json}")
+	parseAndCompare(t, "A=${B:-x}}", "A", "x}")
+	parseAndCompare(t, "A=${B:-${C:-x}", "A", "${B:-x")
+}
+
+func TestRequiredVariables(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("EMPTY", "")
+
+	requiredLines := map[string]string{
+		"API_KEY=${API_KEY:?API key is required}": "API_KEY: API key is required",
+		"API_KEY=${EMPTY:?empty is not enough}":   "EMPTY: empty is not enough",
+		"API_KEY=\"${API_KEY?}\"":                 "API_KEY: parameter not set",
+	}
+	for line, expectedMessage := range requiredLines {
+		_, _, err := parseLine(line, map[string]string{}, Options{})
+		if err == nil || err.Error() != expectedMessage {
+			t.Errorf("Expected '%v' to fail with '%v', got %v", line, expectedMessage, err)
+		}
+	}
+
+	_, err := Parse(strings.NewReader("OPTION_A=1\nAPI_KEY=${API_KEY:?API key is required}\n"))
+	if err == nil || err.Error() != "line 2: API_KEY: API key is required" {
+		t.Errorf("Expected Parse to report the missing variable, got %v", err)
+	}
+}
+
+func TestExpansionAllowPrefix(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_HOST", "example.com")
+	os.Setenv("DATABASE_PASSWORD", "secret")
+
+	opts := Options{ExpansionAllowPrefix: "APP_"}
+	envMap, err := ParseWithOptions(strings.NewReader("LOCAL=local\nURL=https://$APP_HOST/$LOCAL\nLEAK=${DATABASE_PASSWORD:-none}\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	if envMap["URL"] != "https://example.com/local" {
+		t.Errorf("Expected prefixed and same file values to expand, got '%v'", envMap["URL"])
+	}
+	if envMap["LEAK"] != "none" {
+		t.Errorf("Expected a variable outside the prefix to be invisible, got '%v'", envMap["LEAK"])
+	}
+
+	// no restriction by default
+	envMap, _ = Parse(strings.NewReader("LEAK=$DATABASE_PASSWORD\n"))
+	if envMap["LEAK"] != "secret" {
+		t.Errorf("Expected every variable to be visible by default, got '%v'", envMap["LEAK"])
+	}
+}
+
+func TestExpandAcrossFiles(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("USER", "shelluser")
+
+	envMap, err := Read("fixtures/chainbase.env", "fixtures/chainchild.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	if envMap["URL"] != "http://localhost/shelluser" {
+		t.Errorf("Expected a later file to see an earlier one, got '%v'", envMap["URL"])
+	}
+}
+
+func TestExpandFromFilesOnly(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("USER", "shelluser")
+
+	opts := Options{ExpandFromFilesOnly: true}
+	envMap, err := ReadWithOptions(opts, "fixtures/chainbase.env", "fixtures/chainchild.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	if envMap["URL"] != "http://localhost/" {
+		t.Errorf("Expected the env to be ignored, got '%v'", envMap["URL"])
+	}
+
+	_, err = ParseWithOptions(strings.NewReader("NAME=${USER:?must come from a file}\n"), opts)
+	if err == nil {
+		t.Error("Expected a variable only in the env to count as unset")
+	}
+}
+
+func TestExpandPercentStyle(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("USERPROFILE", `C:\Users\gopher`)
+
+	envMap, err := ReadWithOptions(Options{ExpandPercentStyle: true}, "fixtures/percent.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"PATH_LIKE": `C:\Users\gopher\bin`,
+		"QUOTED":    `C:\Users\gopher\tools`,
+		"LITERAL":   "%USERPROFILE%",
+		"UNKNOWN":   "%NOT_SET_ANYWHERE%",
+		"DISCOUNT":  "50% off, 10% more",
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+
+	envMap, _ = Read("fixtures/percent.env")
+	if envMap["PATH_LIKE"] != `%USERPROFILE%\bin` {
+		t.Errorf("Expected percent style to be opt in, got '%v'", envMap["PATH_LIKE"])
+	}
+}
+
+func TestExpandSources(t *testing.T) {
+	os.Clearenv()
+
+	opts := Options{ExpandSources: []string{"fixtures/secrets.env"}}
+	if err := LoadWithOptions(opts, "fixtures/usessecrets.env"); err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"DB_USER":     "app",
+		"DB_PASSWORD": "hunter2",
+		"API_TOKEN":   "tok en",
+	}
+	for key, value := range expectedValues {
+		if os.Getenv(key) != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, os.Getenv(key))
+		}
+	}
+
+	for _, key := range []string{"SECRET_DB_PASSWORD", "SECRET_API_TOKEN"} {
+		if _, exists := os.LookupEnv(key); exists {
+			t.Errorf("Expected %v not to be set in the env", key)
+		}
+	}
+
+	os.Clearenv()
+	envMap, _ := ReadWithOptions(opts, "fixtures/usessecrets.env")
+	if len(envMap) != 3 {
+		t.Errorf("Expected only the main file's keys to be read, got %v", envMap)
+	}
+
+	opts.ExpandSources = []string{"somefilethatwillneverexistever.env"}
+	if _, err := ReadWithOptions(opts, "fixtures/usessecrets.env"); err == nil {
+		t.Error("Expected a missing source file to be an error")
+	}
+}
+
+func TestExpansionDelims(t *testing.T) {
+	os.Clearenv()
+
+	opts := Options{ExpansionDelims: Delims{Open: "%(", Close: ")"}}
+	envMap, err := ReadWithOptions(opts, "fixtures/delims.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"URL":      "http://localhost:8080/",
+		"TEMPLATE": "${HOST} and $HOST are for someone else",
+		"ESCAPED":  "%(HOST)",
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+
+	opts = Options{ExpansionDelims: Delims{Open: "{{", Close: "}}"}}
+	_, value, _ := parseLine("GREETING=hello {{USER:-gopher}} ${USER}", map[string]string{}, opts)
+	if value != "hello gopher ${USER}" {
+		t.Errorf("Expected {{ }} delimiters to work, got '%v'", value)
+	}
+}
+
+func TestExpandAfterParse(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PATH", "/usr/bin")
+
+	envMap, err := ReadWithOptions(Options{ExpandAfterParse: true}, "fixtures/forwardrefs.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	expectedValues := map[string]string{
+		"URL":     "http://example.com:8080/",
+		"HOST":    "example.com",
+		"DOMAIN":  "example.com",
+		"PORT":    "8080",
+		"LITERAL": "${HOST}",
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+
+	opts := Options{ExpandAfterParse: true}
+	os.Clearenv()
+	os.Setenv("PATH", "/usr/bin")
+	if err := LoadWithOptions(opts, "fixtures/forwardrefs.env"); err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	if os.Getenv("PATH") != "/usr/bin" {
+		t.Errorf("Expected PATH to be left alone by Load, got '%v'", os.Getenv("PATH"))
+	}
+	env, err := readFiles([]string{"fixtures/forwardrefs.env"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.values["PATH"] != "/usr/bin:/opt/bin" {
+		t.Errorf("Expected a reference to itself to see the env, got '%v'", env.values["PATH"])
+	}
+
+	// line order is still the default
+	os.Clearenv()
+	envMap, _ = Read("fixtures/forwardrefs.env")
+	if envMap["URL"] != "http://:/" {
+		t.Errorf("Expected forward references not to resolve by default, got '%v'", envMap["URL"])
+	}
+}
+
+func TestExpandAfterParseCycle(t *testing.T) {
+	os.Clearenv()
+	_, err := ReadWithOptions(Options{ExpandAfterParse: true}, "fixtures/cyclicrefs.env")
+	if err == nil || !strings.Contains(err.Error(), "reference cycle") {
+		t.Errorf("Expected a reference cycle error, got %v", err)
+	}
+}
+
+func TestExpansionEnvPrefix(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_HOST", "namespaced.example.com")
+	os.Setenv("PORT", "9090")
+	os.Setenv("MYAPP_PORT", "not used")
+
+	opts := Options{ExpansionEnvPrefix: "MYAPP_"}
+	envMap, err := ParseWithOptions(strings.NewReader("URL=http://${HOST}:${PORT}/\nNAME=file\nGREETING=$NAME\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	if envMap["URL"] != "http://namespaced.example.com:9090/" {
+		t.Errorf("Expected HOST to come from MYAPP_HOST and PORT from PORT, got '%v'", envMap["URL"])
+	}
+	if envMap["GREETING"] != "file" {
+		t.Errorf("Expected values from the file to be found as usual, got '%v'", envMap["GREETING"])
+	}
+
+	envMap, _ = Parse(strings.NewReader("URL=${HOST}\n"))
+	if envMap["URL"] != "" {
+		t.Errorf("Expected the prefix to be opt in, got '%v'", envMap["URL"])
+	}
+}
+
+func TestExpandFunctions(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DEPLOYED_BY", "ci")
+
+	functions := map[string]func() string{"__REGION__": func() string { return "eu-west-1" }}
+	for name, fn := range BuiltinExpandFunctions {
+		functions[name] = fn
+	}
+	before := time.Now().UTC()
+	envMap, err := ReadWithOptions(Options{ExpandFunctions: functions}, "fixtures/pseudovars.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	uuid := regexp.MustCompile(`^run-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(envMap["SESSION"]) || !uuid.MatchString(envMap["OTHER_SESSION"]) {
+		t.Errorf("Expected version 4 UUIDs, got '%v' and '%v'", envMap["SESSION"], envMap["OTHER_SESSION"])
+	}
+	if envMap["SESSION"] == envMap["OTHER_SESSION"] {
+		t.Error("Expected a fresh UUID for each reference")
+	}
+
+	builtOn, err := time.Parse("2006-01-02T15:04:05Z", envMap["BUILT_ON"])
+	if err != nil {
+		t.Fatalf("Expected a UTC date and time, got '%v'", envMap["BUILT_ON"])
+	}
+	if builtOn.Before(before.Truncate(time.Second)) || builtOn.After(time.Now().UTC()) {
+		t.Errorf("Expected the current UTC time, got %v", builtOn)
+	}
+
+	if envMap["REGION"] != "eu-west-1" || envMap["PLAIN"] != "ci" {
+		t.Errorf("Expected the added function and env to expand, got '%v' and '%v'", envMap["REGION"], envMap["PLAIN"])
+	}
+
+	// opt in only
+	envMap, err = Read("fixtures/pseudovars.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	if envMap["SESSION"] != "run-" {
+		t.Errorf("Expected no pseudo-variables by default, got '%v'", envMap["SESSION"])
+	}
+
+	// and only for __NAMES__
+	envMap, err = ParseWithOptions(strings.NewReader("A=${DEPLOYED_BY}"), Options{
+		ExpandFunctions: map[string]func() string{"DEPLOYED_BY": func() string { return "shadowed" }},
+	})
+	if err != nil || envMap["A"] != "ci" {
+		t.Errorf("Expected functions without underscores to be ignored, got '%v' %v", envMap["A"], err)
+	}
+}
@@ -0,0 +1,111 @@
+package godotenv
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// FormatFile rewrites filename in a consistent style, like gofmt for .env files:
+//
+//   - KEY=value with no whitespace around the =, and = rather than :
+//   - quotes dropped from values that don't need them, "8080" becomes 8080
+//   - a single space between a value and its comment
+//   - comments and key order kept, runs of blank lines squeezed to one
+//   - no blank lines at the start or end, and a final newline
+//
+// Values are otherwise left as written, references aren't expanded and multi-
+// line values and YAML block scalars are untouched, as are lines that don't
+// parse. Formatting a formatted file changes nothing. The file is replaced
+// atomically, as with MergeInto.
+func FormatFile(filename string) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(filename, []byte(formatContent(string(content))))
+}
+
+func formatContent(content string) string {
+	assignments := make(map[int]assignment)
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return content
+	}
+	p.collectErrors = true
+	p.onAssign = func(a assignment) {
+		assignments[a.first] = a
+	}
+	if err := p.parse(strings.NewReader(content)); err != nil {
+		return content
+	}
+
+	lines := splitLines(decodeUTF16([]byte(content)))
+	var formatted []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		a, isAssignment := assignments[i+1]
+
+		switch _, _, isBlock := parseBlockScalarHeader(a.text); {
+		case isAssignment && isBlock:
+			// the header and everything indented under it, as it is, leaving
+			// the blank lines after it to be squeezed like any others
+			formatted = append(formatted, line)
+			body := a.last - 1
+			for body > i && strings.TrimSpace(lines[body]) == "" {
+				body--
+			}
+			for ; i < body; i++ {
+				formatted = append(formatted, strings.TrimRight(lines[i+1], " \t"))
+			}
+		case isAssignment:
+			text := strings.TrimRight(strings.Join(lines[i:a.last], "\n"), " \t")
+			formatted = append(formatted, formatAssignment(text, a.key))
+			i = a.last - 1
+		case strings.TrimSpace(line) == "":
+			if len(formatted) > 0 && formatted[len(formatted)-1] != "" {
+				formatted = append(formatted, "")
+			}
+		case isIgnoredLine(line):
+			formatted = append(formatted, strings.TrimLeft(line, " \t"))
+		default:
+			// it doesn't parse, so it stays as it is
+			formatted = append(formatted, line)
+		}
+	}
+
+	for len(formatted) > 0 && formatted[len(formatted)-1] == "" {
+		formatted = formatted[:len(formatted)-1]
+	}
+	if len(formatted) == 0 {
+		return ""
+	}
+	return strings.Join(formatted, "\n") + "\n"
+}
+
+// formatAssignment puts the assignment to key on line in the canonical style
+func formatAssignment(line, key string) string {
+	assignment := stripComment(line, "#", false, Options{})
+	rawKey, rawValue, _ := splitKeyValue(assignment)
+	var export string
+	if rawKey = strings.Trim(rawKey, " \t"); strings.HasPrefix(rawKey, "export ") || strings.HasPrefix(rawKey, "export\t") {
+		export = "export "
+	}
+
+	formatted := export + keyEscaper.Replace(key) + "=" + unquoteIfSafe(strings.Trim(rawValue, " \t"))
+	if comment := strings.TrimSpace(line[len(assignment):]); comment != "" {
+		formatted += " " + comment
+	}
+	return formatted
+}
+
+// unquoteIfSafe drops the quotes around a raw value when what's inside can
+// be written without them, see QuoteValue
+func unquoteIfSafe(rawValue string) string {
+	if len(rawValue) < 3 || rawValue[0] != rawValue[len(rawValue)-1] || (rawValue[0] != '"' && rawValue[0] != '\'') {
+		return rawValue
+	}
+	if inner := rawValue[1 : len(rawValue)-1]; isSafeUnquoted(inner) {
+		return inner
+	}
+	return rawValue
+}
@@ -0,0 +1,90 @@
+package godotenv
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFormatFile(t *testing.T) {
+	os.Clearenv()
+	messy, err := ioutil.ReadFile("fixtures/messy.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := ioutil.ReadFile("fixtures/messy.formatted.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(messy); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if err := FormatFile(file.Name()); err != nil {
+		t.Fatalf("Error formatting: %v", err)
+	}
+	formatted, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(canonical) {
+		t.Errorf("Expected\n%s\ngot\n%s", canonical, formatted)
+	}
+
+	// formatting again changes nothing
+	if err := FormatFile(file.Name()); err != nil {
+		t.Fatalf("Error formatting again: %v", err)
+	}
+	again, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(formatted) {
+		t.Errorf("Expected formatting to be idempotent, got\n%s", again)
+	}
+
+	// and the values read are the same as before
+	before, err := Read("fixtures/messy.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := Read(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("Expected %d keys after formatting got %d", len(before), len(after))
+	}
+	for key, value := range before {
+		if after[key] != value {
+			t.Errorf("Mismatch for key '%v': expected %q got %q", key, value, after[key])
+		}
+	}
+}
+
+func TestFormatKeepsUnparsableLines(t *testing.T) {
+	content := "A = 1\nnot an assignment   \n\r\n"
+	if formatted := formatContent(content); formatted != "A=1\nnot an assignment\n" {
+		t.Errorf("Unexpected %q", formatted)
+	}
+}
+
+func TestFormatUnsetReference(t *testing.T) {
+	os.Clearenv()
+	if formatted := formatContent("DB_URL = ${HOST:?need host}\n"); formatted != "DB_URL=${HOST:?need host}\n" {
+		t.Errorf("Unexpected %q", formatted)
+	}
+}
+
+func TestFormatFileMissingFile(t *testing.T) {
+	if err := FormatFile("somefilethatwillneverexistever.env"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+)
+
+// GetBoolExtended reads a boolean from the env, accepting the spellings other
+// tools like to write as well as those strconv.ParseBool knows: 1, t, true, yes,
+// y and on are true, 0, f, false, no, n and off are false, in any case. Anything
+// else, including an unset variable, gives def.
+func GetBoolExtended(key string, def bool) bool {
+	if value, ok := parseBoolExtended(os.Getenv(key)); ok {
+		return value
+	}
+	return def
+}
+
+func parseBoolExtended(s string) (value bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "yes", "y", "on":
+		return true, true
+	case "0", "f", "false", "no", "n", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// KeysWithPrefix returns every variable in the environment whose name starts
+// with prefix, e.g. all the APP_ ones to hand to a subsystem. It reads the live
+// environment, so it sees everything that's set, not just what came from files.
+func KeysWithPrefix(prefix string) map[string]string {
+	matching := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value := SplitEntry(kv); strings.HasPrefix(key, prefix) {
+			matching[key] = value
+		}
+	}
+	return matching
+}
+
+// SplitEntry splits an environ style KEY=value entry, as os.Environ returns
+// them, on its first =. An entry without one is all key with an empty value.
+func SplitEntry(entry string) (key, value string) {
+	if i := strings.IndexByte(entry, '='); i >= 0 {
+		return entry[:i], entry[i+1:]
+	}
+	return entry, ""
+}
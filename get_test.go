@@ -0,0 +1,78 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetBoolExtended(t *testing.T) {
+	os.Clearenv()
+
+	for _, spelling := range []string{"1", "t", "T", "true", "TRUE", "True", "yes", "Yes", "y", "Y", "on", "ON"} {
+		os.Setenv("FLAG", spelling)
+		if !GetBoolExtended("FLAG", false) {
+			t.Errorf("Expected '%v' to be true", spelling)
+		}
+	}
+
+	for _, spelling := range []string{"0", "f", "F", "false", "FALSE", "False", "no", "No", "n", "N", "off", "OFF"} {
+		os.Setenv("FLAG", spelling)
+		if GetBoolExtended("FLAG", true) {
+			t.Errorf("Expected '%v' to be false", spelling)
+		}
+	}
+
+	for _, spelling := range []string{"", "maybe", "2", "yess"} {
+		os.Setenv("FLAG", spelling)
+		if !GetBoolExtended("FLAG", true) || GetBoolExtended("FLAG", false) {
+			t.Errorf("Expected '%v' to give the default", spelling)
+		}
+	}
+
+	os.Unsetenv("FLAG")
+	if !GetBoolExtended("FLAG", true) {
+		t.Error("Expected an unset variable to give the default")
+	}
+}
+
+func TestKeysWithPrefix(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("APP_DSN", "user=app password=x=y")
+	os.Setenv("APP_EMPTY", "")
+	os.Setenv("OTHER_APP_KEY", "nope")
+	if err := Load("fixtures/plain.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	matching := KeysWithPrefix("APP_")
+	expected := map[string]string{"APP_DSN": "user=app password=x=y", "APP_EMPTY": ""}
+	if len(matching) != len(expected) {
+		t.Errorf("Expected %v got %v", expected, matching)
+	}
+	for key, value := range expected {
+		if actual, ok := matching[key]; !ok || actual != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, actual)
+		}
+	}
+
+	if len(KeysWithPrefix("OPTION_")) != 5 {
+		t.Errorf("Expected the loaded keys to be found, got %v", KeysWithPrefix("OPTION_"))
+	}
+}
+
+func TestSplitEntry(t *testing.T) {
+	for entry, expected := range map[string][2]string{
+		"KEY=value":          {"KEY", "value"},
+		"DSN=user=app pw=x=": {"DSN", "user=app pw=x="},
+		"EMPTY=":             {"EMPTY", ""},
+		"NOEQUALS":           {"NOEQUALS", ""},
+		"=C:=C:\\":           {"", "C:=C:\\"},
+		"":                   {"", ""},
+	} {
+		key, value := SplitEntry(entry)
+		if key != expected[0] || value != expected[1] {
+			t.Errorf("Expected %q to split into %q and %q, got %q and %q", entry, expected[0], expected[1], key, value)
+		}
+	}
+}
@@ -16,12 +16,183 @@ and all the env vars declared in .env will be avaiable through os.Getenv("SOME_E
 package godotenv
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 )
 
+var (
+	errEmptyKey    = errors.New("empty key")
+	errNoSeparator = errors.New("Can't separate key from value")
+)
+
+// Options tweak how env files are read, the zero value behaves just like
+// Load, Read and Parse do
+type Options struct {
+	// RequireFinalNewline makes content that doesn't end in a newline an
+	// error, for teams whose tooling appends to .env files
+	RequireFinalNewline bool
+	// ErrorOnEmpty makes a file with no assignments in it, once comments and
+	// blank lines are left out, an error. Empty files are fine by default but
+	// one produced by a broken template render usually isn't.
+	ErrorOnEmpty bool
+	// QuotedKeys lets keys be wrapped in quotes, e.g. "a=b"=c sets the key a=b,
+	// so they can contain separators and other characters a plain key can't
+	QuotedKeys bool
+	// KeepQuotes returns values exactly as they're written, quotes and
+	// escapes included, for tools passing a file through to something that
+	// expects the original quoting. Nothing is expanded either.
+	KeepQuotes bool
+	// QuoteChars lists the characters that quote values, by default both " and
+	// '. Setting it to just " makes single quotes plain apostrophes, so
+	// FOO='tis fine # really keeps its ' and still loses the comment. Only "
+	// and ' mean anything here.
+	QuoteChars string
+	// RejectControlChars makes an assignment written with a raw control
+	// character anywhere in it, anything but tab, an error naming the key and
+	// where it is. That covers quoted keys and YAML block scalars too. Escapes
+	// like "\r" in double quotes are still fine.
+	RejectControlChars bool
+	// Section picks out one INI style [section] of a file. Assignments before
+	// the first section header apply to every section, and those under any
+	// other section are skipped. Without it section headers are ignored.
+	Section string
+	// PlatformConditionals includes the lines between #if GOOS=windows (or
+	// GOARCH=arm64) and #endif only when running on that platform. Being
+	// comments, the directives are ignored by anything else reading the file,
+	// so both sides get every line. Any other #if is left as a comment.
+	// Blocks can't be nested, and an #if without its #endif is an error.
+	PlatformConditionals bool
+	// ExpansionAllowPrefix limits which environment variables values can refer
+	// to, only those whose names start with it are visible. Values set earlier
+	// in the same file are always visible.
+	ExpansionAllowPrefix string
+	// ExpansionEnvPrefix gives the environment a second chance at references
+	// it doesn't have, under a namespaced name: with "MYAPP_", ${HOST} is looked
+	// for in the file, then as HOST in the environment, then as MYAPP_HOST.
+	// Values from the files aren't looked up under the prefix.
+	ExpansionEnvPrefix string
+	// ExpandFunctions are pseudo-variables worked out at load time, so a value
+	// like SESSION=run-${__UUID__} is different each load. Only references
+	// named __LIKE_THIS__ are looked for here, which keeps them from clashing
+	// with real variables, and each reference calls the function again. Use
+	// BuiltinExpandFunctions, or a copy of it with your own added.
+	ExpandFunctions map[string]func() string
+	// ExpandFromFilesOnly stops values referring to the environment at all,
+	// only values from the files being read are visible, so the result doesn't
+	// depend on whoever's shell it runs in. References to anything else expand
+	// to an empty string (or fail with ${VAR:?}) as usual.
+	ExpandFromFilesOnly bool
+	// ExpandPercentStyle also expands Windows style %VAR% references, as in
+	// PATH_LIKE=%USERPROFILE%\bin. It's opt in on every platform.
+	ExpandPercentStyle bool
+	// ExpansionDelims replaces ${ and } with other delimiters, like %( and ),
+	// for values meant for other templating systems. Bare $VAR isn't expanded
+	// when they're set, and ${...} is left alone.
+	ExpansionDelims Delims
+	// ExpandSources are files read only so values can refer to what's in them,
+	// their own keys are never set or returned. Keeping secrets in one lets the
+	// main file say DB_PASSWORD=${SECRET_DB_PASSWORD} without exporting the
+	// SECRET_ variables themselves.
+	ExpandSources []string
+	// AppendKeys maps keys like PATH to a separator like ":". When one of them
+	// is set again by a later file, or is already in the environment, the new
+	// value is added on the end rather than replacing or being skipped. Keys
+	// set twice within one file are still just replaced.
+	AppendKeys map[string]string
+	// Aliases renames keys as they're read, so a third party's DATABASE_HOST
+	// can become your DB_HOST. The original name isn't set unless
+	// KeepAliasedKeys is, and the new name follows the usual rules, Load won't
+	// override it if it's already set. Values can still refer to the original
+	// names within the file.
+	Aliases         map[string]string
+	KeepAliasedKeys bool
+	// RequireSecurePermissions refuses to read a file that its group or anyone
+	// else can access, e.g. 0644 fails where 0600 is fine. It does nothing on
+	// Windows, where the unix permission bits don't apply.
+	RequireSecurePermissions bool
+	// CommentPrefixes adds comment markers alongside #, like "//" for files
+	// from C style config generators. Unlike # an inline comment using one of
+	// these has to follow whitespace, so URL=http://host survives "//".
+	CommentPrefixes []string
+	// MaxLineBytes is the longest line that can be read, 1MB if it's not set.
+	// Raise it for files with huge single line values.
+	MaxLineBytes int
+	// MaxValueBytes, if set, is an error for any value longer than it, giving
+	// the key and line. It's there to catch a multi-line value whose closing
+	// quote went missing and swallowed the rest of the file. Values are
+	// measured as parsed, before expansion with ExpandAfterParse.
+	MaxValueBytes int
+	// ErrorOnCrossFileConflict makes it an error for two of the files read or
+	// loaded together to set the same key to different values, naming both
+	// files and values, and when loading nothing is set. The same value in
+	// both is fine. Later files normally override earlier ones, this is for
+	// when they're meant to be peers, as in a conf.d directory.
+	ErrorOnCrossFileConflict bool
+	// OnWarning is called for lines that parse but probably don't mean what
+	// they say, for now unquoted values with a bare $VAR in them (PASSWORD=a$bc
+	// expands $bc). Returning an error stops parsing with it, returning nil
+	// carries on.
+	OnWarning func(w Warning) error
+	// ExpandAfterParse expands references once the whole file is parsed, so a
+	// value can refer to a key set further down. By default references are
+	// expanded line by line, as a shell would, and only see what came before.
+	// References that go round in a circle are an error.
+	ExpandAfterParse bool
+	// AllowFileReferences replaces an unquoted value like @/run/secrets/db with
+	// the content of that file, trimmed, as Docker secrets are delivered.
+	// Relative paths are from the directory of the .env file (or the working
+	// directory for Parse). A missing file is an error, and \@ at the start of
+	// a value gives a literal @.
+	AllowFileReferences bool
+	// PreserveTrailingSpace keeps the spaces and tabs at the end of an unquoted
+	// value rather than trimming them, for legacy files that rely on them
+	// (quoting the value is the better fix). Inline comments are stripped
+	// first, so A=b  # note gives "b  ". Leading whitespace and quoted values
+	// are trimmed as usual.
+	PreserveTrailingSpace bool
+	// ExpandUnquotedEscapes processes escapes like \n and \t in unquoted
+	// values the way double quotes do. By default only \$ means anything there
+	// and other backslashes are kept as written. Single quoted values never
+	// have escapes processed.
+	ExpandUnquotedEscapes bool
+	// NormalizeKeys and NormalizeValues, if set, are applied to every key and
+	// value parsed, so a file written with decomposed Unicode (NFD, as macOS
+	// often produces) matches keys and values composed the usual way. For that
+	// pass NFC, which is built with the godotenv_nfc tag so golang.org/x/text
+	// is only a dependency for those who ask for it.
+	NormalizeKeys   func(s string) string
+	NormalizeValues func(s string) string
+	// Separators replaces the usual = (or failing that :) between key and
+	// value, for legacy formats like KEY => value. Each line splits on
+	// whichever occurs first, and when two start at the same place, say => and
+	// =, on the one listed first. Escape a separator in a key with a backslash.
+	Separators []string
+
+	// deferExpansion has parseValue leave values unexpanded, and lookup is
+	// consulted before anything else when expanding, both for ExpandAfterParse
+	deferExpansion bool
+	lookup         func(name string) (string, bool)
+	// baseDir is where the file being read lives, for AllowFileReferences
+	baseDir string
+}
+
+// Delims are the opening and closing delimiters of a variable reference
+type Delims struct {
+	Open, Close string
+}
+
 /*
 	Call this function as close as possible to the start of your program (ideally in main)
 
@@ -34,149 +205,1578 @@ import (
 	It's important to note that it WILL NOT OVERRIDE an env variable that already exists - consider the .env file to set dev vars or sensible defaults
 */
 func Load(filenames ...string) (err error) {
-	filenames = filenamesOrDefault(filenames)
+	return LoadWithOptions(Options{}, filenames...)
+}
 
-	for _, filename := range filenames {
-		err = loadFile(filename)
+// LoadWithOptions is Load with control over how the files are read
+func LoadWithOptions(opts Options, filenames ...string) (err error) {
+	_, err = loadFiles(filenamesOrDefault(filenames), opts)
+	return
+}
+
+// LoadWithAliases is Load, but keys in aliases are set under their alias
+// instead of their own name, see Options.Aliases
+func LoadWithAliases(aliases map[string]string, filenames ...string) error {
+	return LoadWithOptions(Options{Aliases: aliases}, filenames...)
+}
+
+// LoadPrefixed is Load, but every key is set in the environment with prefix
+// in front of it, so with "INSTANCE2_" PORT is set as INSTANCE2_PORT. That
+// lets two copies of the same config live in one process. References inside
+// the files still use the names as written, and a prefixed name that's
+// already set is left alone, as Load would.
+func LoadPrefixed(prefix string, filenames ...string) error {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return err
+	}
+	for _, key := range env.keys {
+		setEnv(prefix+key, env.values[key], false)
+	}
+	return nil
+}
+
+// LoadRequired is Load, then checks every key in required is set in the
+// environment, whether the files set it or it was there already. Keys that
+// aren't are all listed in a *MissingKeysError. A key set to an empty string
+// counts as set.
+func LoadRequired(required []string, filenames ...string) error {
+	if err := Load(filenames...); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, key := range required {
+		if _, ok := os.LookupEnv(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// MissingKeysError lists the required keys LoadRequired didn't find, in the
+// order they were asked for
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return "missing required keys: " + strings.Join(e.Keys, ", ")
+}
+
+// ValidateAllowedKeys parses the files, without loading anything, and errors
+// if they set any key not in allowed, listing the unexpected ones in the order
+// they appear. It's the counterpart to LoadRequired: that checks for a minimum
+// set of keys, this a maximum, so a tampered .env can't slip in extras.
+func ValidateAllowedKeys(allowed []string, filenames ...string) error {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return err
+	}
+
+	isAllowed := make(map[string]bool, len(allowed))
+	for _, key := range allowed {
+		isAllowed[key] = true
+	}
+	var unexpected []string
+	for _, key := range env.keys {
+		if !isAllowed[key] {
+			unexpected = append(unexpected, key)
+		}
+	}
+	if len(unexpected) > 0 {
+		return errors.New("unexpected keys: " + strings.Join(unexpected, ", "))
+	}
+	return nil
+}
+
+// LoadFirst loads only the first of the files that exists, like Load does,
+// returning its name. It's for "local overrides else defaults" setups such as
+//
+//	godotenv.LoadFirst(".env.local", ".env", ".env.defaults")
+//
+// Missing files are skipped, and it's an error if none of them exist. A file
+// that exists but can't be read or parsed is an error rather than skipped.
+func LoadFirst(filenames ...string) (loaded string, err error) {
+	for _, filename := range filenamesOrDefault(filenames) {
+		_, err = loadFiles([]string{filename}, Options{})
+		if os.IsNotExist(err) {
+			continue
+		}
 		if err != nil {
-			return // return early on a spazout
+			return
 		}
+		return filename, nil
 	}
+
+	err = fmt.Errorf("none of %v exist", strings.Join(filenamesOrDefault(filenames), ", "))
 	return
 }
 
-func Read(filenames ...string) (envMap map[string]string, err error) {
-	filenames = filenamesOrDefault(filenames)
-	envMap = make(map[string]string)
+// LoadFiles loads each of the files (or .env) like Load, carrying on past any
+// that fail, and returns every filename mapped to its error, nil for those that
+// loaded. Files that fail contribute nothing, the rest are applied in order.
+func LoadFiles(filenames ...string) (results map[string]error) {
+	results = make(map[string]error)
+	loaded := newOrderedEnv()
+	for _, filename := range filenamesOrDefault(filenames) {
+		env, err := readFile(filename, Options{}, loaded.values)
+		results[filename] = err
+		if err != nil {
+			continue
+		}
+		env.apply()
+		loaded.merge(env)
+	}
+	return
+}
 
-	for _, filename := range filenames {
-		individualEnvMap, individualErr := readFile(filename)
+// LoadAndRead loads the files exactly like Load, and also returns everything
+// they contained, including the keys that weren't set because they were
+// already in the env. Saves parsing twice when you want to inspect the config.
+func LoadAndRead(filenames ...string) (envMap map[string]string, err error) {
+	env, err := loadFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return
+	}
+	return env.values, nil
+}
 
-		if individualErr != nil {
-			err = individualErr
-			return // return early on a spazout
-		}
+func Read(filenames ...string) (envMap map[string]string, err error) {
+	return ReadWithOptions(Options{}, filenames...)
+}
 
-		for key, value := range individualEnvMap {
-			envMap[key] = value
+// ReadWithOptions is Read with control over how the files are read
+func ReadWithOptions(opts Options, filenames ...string) (envMap map[string]string, err error) {
+	env, err := readFiles(filenamesOrDefault(filenames), opts)
+	if err != nil {
+		return
+	}
+
+	envMap = env.unset()
+	for key, separator := range opts.AppendKeys {
+		if value, ok := env.values[key]; ok {
+			if existing, exists := os.LookupEnv(key); exists {
+				envMap[key] = appendValue(existing, separator, value)
+			}
 		}
 	}
 
 	return
 }
 
-func filenamesOrDefault(filenames []string) []string {
-	if len(filenames) == 0 {
-		return []string{".env"}
-	} else {
-		return filenames
+// ReadN parses just the first n keys assigned in the file, for previewing big
+// generated files, and stops reading there. Blank lines and comments don't count
+// towards n. Unlike Read the env isn't consulted, you get what the file says.
+func ReadN(n int, filename string) (envMap map[string]string, err error) {
+	if n <= 0 {
+		return make(map[string]string), nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	env, err := parseLimited(file, Options{}, nil, n)
+	if err != nil {
+		err = fmt.Errorf("parsing %q: %w", filename, err)
+		return
 	}
+	return env.values, nil
 }
 
-func loadFile(filename string) (err error) {
-	envMap, err := readFile(filename)
+// ReadCollectErrors is Read for a single file that carries on past bad lines,
+// returning the values from the good lines along with every bad line. Lines
+// Read would skip quietly, like those without a separator, count as bad here.
+// When there are bad lines err lists them all as well.
+func ReadCollectErrors(filename string) (envMap map[string]string, lineErrors []LineError, err error) {
+	file, err := os.Open(filename)
 	if err != nil {
 		return
 	}
+	defer file.Close()
 
-	for key, value := range envMap {
-		os.Setenv(key, value)
+	p, err := newParser(Options{}, nil)
+	if err != nil {
+		return
+	}
+	p.collectErrors = true
+	if err = p.parse(file); err != nil {
+		err = fmt.Errorf("parsing %q: %w", filename, err)
+		return
 	}
 
+	envMap = p.env.unset()
+	lineErrors = p.lineErrors
+	if len(lineErrors) > 0 {
+		problems := make([]string, len(lineErrors))
+		for i, lineError := range lineErrors {
+			problems[i] = lineError.Error()
+		}
+		err = fmt.Errorf("parsing %q: %d bad lines\n%v", filename, len(lineErrors), strings.Join(problems, "\n"))
+	}
 	return
 }
 
-func readFile(filename string) (envMap map[string]string, err error) {
+// ReadWithSource is Read for a single file that also hands back the file's
+// content split into lines, for tooling that shows values in context without
+// reading the file twice. Line numbers in parse errors are 1-based, so
+// "line N" refers to lines[N-1].
+func ReadWithSource(filename string) (envMap map[string]string, lines []string, err error) {
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return
 	}
 
-	envMap = make(map[string]string)
+	env, err := parse(bytes.NewReader(content), Options{}, nil)
+	if err != nil {
+		err = fmt.Errorf("parsing %q: %w", filename, err)
+		return
+	}
 
-	lines := strings.Split(string(content), "\n")
+	envMap = env.unset()
+	lines = splitLines(decodeUTF16(content))
+	return
+}
 
-	for _, fullLine := range lines {
-		if !isIgnoredLine(fullLine) {
-			key, value, err := parseLine(fullLine)
+// ValueSource describes where the effective value of a key comes from
+type ValueSource struct {
+	// Effective is the value the program sees once the files are loaded
+	Effective string
+	// FromFile is the value the files give the key
+	FromFile string
+	// Source is "env" when the variable was already set, so the file value
+	// doesn't apply, or "file" when the file value does
+	Source string
+}
 
-			if err == nil && os.Getenv(key) == "" {
-				envMap[key] = value
-			}
+// ReadSources explains, for every key in the files, whether loading them would
+// use the file's value or leave the value already in the env. Keys only in the
+// env aren't included since the files have nothing to say about them. Like Read
+// nothing is changed.
+func ReadSources(filenames ...string) (sources map[string]ValueSource, err error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return
+	}
+
+	sources = make(map[string]ValueSource)
+	for key, value := range env.values {
+		if current, exists := os.LookupEnv(key); exists {
+			sources[key] = ValueSource{Effective: current, FromFile: value, Source: "env"}
+		} else {
+			sources[key] = ValueSource{Effective: value, FromFile: value, Source: "file"}
 		}
 	}
 	return
 }
 
-func parseLine(line string) (key string, value string, err error) {
-	if len(line) == 0 {
-		err = errors.New("zero length string")
+// CompareFiles parses two env files and reports the keys only found in a, the
+// keys only found in b, and the keys found in both but with different values
+// (differing holds the value from b). Keys with the same value in both files
+// don't appear in any of the results. The environment isn't consulted or touched.
+func CompareFiles(a, b string) (onlyInA, onlyInB, differing map[string]string, err error) {
+	envA, err := readFile(a, Options{}, nil)
+	if err != nil {
+		return
+	}
+	envB, err := readFile(b, Options{}, nil)
+	if err != nil {
 		return
 	}
+	envMapA, envMapB := envA.values, envB.values
 
-	// ditch the comments (but keep quoted hashes)
-	if strings.Contains(line, "#") {
-		segmentsBetweenHashes := strings.Split(line, "#")
-		quotesAreOpen := false
-		segmentsToKeep := make([]string, 0)
-		for _, segment := range segmentsBetweenHashes {
-			if strings.Count(segment, "\"") == 1 || strings.Count(segment, "'") == 1 {
-				if quotesAreOpen {
-					quotesAreOpen = false
-					segmentsToKeep = append(segmentsToKeep, segment)
-				} else {
-					quotesAreOpen = true
+	onlyInA = make(map[string]string)
+	onlyInB = make(map[string]string)
+	differing = make(map[string]string)
+
+	for key, valueA := range envMapA {
+		valueB, ok := envMapB[key]
+		switch {
+		case !ok:
+			onlyInA[key] = valueA
+		case valueA != valueB:
+			differing[key] = valueB
+		}
+	}
+	for key, valueB := range envMapB {
+		if _, ok := envMapA[key]; !ok {
+			onlyInB[key] = valueB
+		}
+	}
+
+	return
+}
+
+// ParseReaders parses each reader in turn and merges the results, where a key
+// in a later reader takes precedence over the same key in an earlier one. Handy
+// for layering embedded defaults under runtime overrides.
+func ParseReaders(readers ...io.Reader) (envMap map[string]string, err error) {
+	envMap = make(map[string]string)
+
+	for _, r := range readers {
+		individualEnvMap, individualErr := Parse(r)
+		if individualErr != nil {
+			err = individualErr
+			return
+		}
+
+		for key, value := range individualEnvMap {
+			envMap[key] = value
+		}
+	}
+	return
+}
+
+// MarshalOptions controls how Marshal and Write render an env map
+type MarshalOptions struct {
+	// KeyPrefix is prepended to every key in the output, e.g. "SVC_A_" turns PORT into SVC_A_PORT
+	KeyPrefix string
+	// Comments maps a key to a description written as a full-line # comment
+	// above it, keys without an entry are written without one
+	Comments map[string]string
+	// ErrorOnMissingKeys makes MarshalOrdered fail when a key it's asked for
+	// isn't in the map, rather than skipping it
+	ErrorOnMissingKeys bool
+	// GroupBy puts keys into named groups, written one after another with a
+	// # group header and a blank line between them. Keys are sorted by group
+	// first, keys in the "" group come first and get no header.
+	GroupBy func(key string) string
+}
+
+// Marshal outputs the given environment as a dotenv-formatted environment file.
+// Each line is in the format: KEY="VALUE" where VALUE is backslash-escaped.
+func Marshal(envMap map[string]string) (string, error) {
+	return MarshalWithOptions(envMap, MarshalOptions{})
+}
+
+// MarshalWithOptions is Marshal with control over how the output is rendered
+func MarshalWithOptions(envMap map[string]string, opts MarshalOptions) (string, error) {
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return MarshalOrderedWithOptions(keys, envMap, opts)
+}
+
+// MarshalOrdered is Marshal with the lines in the order of keys rather than
+// sorted, so tools can round trip a file keeping its author's order. Keys in
+// the map but not in keys are left out, and keys not in the map are skipped.
+func MarshalOrdered(keys []string, envMap map[string]string) (string, error) {
+	return MarshalOrderedWithOptions(keys, envMap, MarshalOptions{})
+}
+
+// MarshalOrderedWithOptions is MarshalOrdered with control over how the output
+// is rendered, including whether keys not in the map are an error
+func MarshalOrderedWithOptions(keys []string, envMap map[string]string, opts MarshalOptions) (string, error) {
+	if opts.KeyPrefix != "" && !isValidKeyPrefix(opts.KeyPrefix) {
+		return "", fmt.Errorf("invalid key prefix %q", opts.KeyPrefix)
+	}
+
+	if opts.GroupBy != nil {
+		grouped := make([]string, len(keys))
+		copy(grouped, keys)
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return opts.GroupBy(grouped[i]) < opts.GroupBy(grouped[j])
+		})
+		keys = grouped
+	}
+
+	lines := make([]string, 0, len(keys))
+	group := ""
+	for _, key := range keys {
+		if _, ok := envMap[key]; !ok {
+			if opts.ErrorOnMissingKeys {
+				return "", fmt.Errorf("key %v isn't in the map", key)
+			}
+			continue
+		}
+		if opts.GroupBy != nil {
+			if keyGroup := opts.GroupBy(key); keyGroup != group || len(lines) == 0 {
+				if len(lines) > 0 {
+					lines = append(lines, "")
+				}
+				if keyGroup != "" {
+					lines = append(lines, "# "+keyGroup)
 				}
+				group = keyGroup
 			}
-
-			if len(segmentsToKeep) == 0 || quotesAreOpen {
-				segmentsToKeep = append(segmentsToKeep, segment)
+		}
+		if comment, ok := opts.Comments[key]; ok {
+			for _, commentLine := range strings.Split(comment, "\n") {
+				lines = append(lines, strings.TrimRight("# "+commentLine, " "))
 			}
 		}
+		lines = append(lines, fmt.Sprintf(`%s%s="%s"`, opts.KeyPrefix, keyEscaper.Replace(key), doubleQuoteEscape(envMap[key])))
+	}
+	return strings.Join(lines, "\n"), nil
+}
 
-		line = strings.Join(segmentsToKeep, "#")
+// Write serializes the given environment and writes it to a file
+func Write(envMap map[string]string, filename string) error {
+	return WriteWithOptions(envMap, filename, MarshalOptions{})
+}
+
+// WriteWithOptions is Write with control over how the output is rendered
+func WriteWithOptions(envMap map[string]string, filename string, opts MarshalOptions) error {
+	content, err := MarshalWithOptions(envMap, opts)
+	if err != nil {
+		return err
 	}
+	return ioutil.WriteFile(filename, []byte(content+"\n"), 0644)
+}
 
-	// now split key from value
-	splitString := strings.Split(line, "=")
+// DumpEnviron writes the current process environment to filename as a .env
+// file, handy for snapshotting config when debugging or seeding a container.
+// Only keys filter returns true for are written, a nil filter writes them all.
+// A new file is created readable by its owner only (0600).
+func DumpEnviron(filename string, filter func(key string) bool) error {
+	envMap := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value := SplitEntry(kv)
+		// windows has oddities like =C:=C:\ in there, which aren't real keys
+		if key == "" {
+			continue
+		}
+		if filter != nil && !filter(key) {
+			continue
+		}
+		envMap[key] = value
+	}
 
-	if len(splitString) != 2 {
-		// try yaml mode!
-		splitString = strings.Split(line, ":")
+	// credentials and all, so only the owner gets to read it
+	content, err := Marshal(envMap)
+	if err != nil {
+		return err
 	}
+	return ioutil.WriteFile(filename, []byte(content+"\n"), 0600)
+}
 
-	if len(splitString) != 2 {
-		err = errors.New("Can't separate key from value")
-		return
+// readFiles merges the files in order, without regard to the current env.
+// Values from earlier files can be referred to by later ones.
+func readFiles(filenames []string, opts Options) (env *orderedEnv, err error) {
+	env = newOrderedEnv()
+	origins := make(map[string]keyOrigin)
+
+	for _, filename := range filenames {
+		individualEnv, individualErr := readFile(filename, opts, env.values)
+		if individualErr == nil && opts.ErrorOnCrossFileConflict {
+			individualErr = checkCrossFileConflicts(origins, filename, individualEnv)
+		}
+
+		if individualErr != nil {
+			err = individualErr
+			return // return early on a spazout
+		}
+
+		individualEnv = individualEnv.rename(opts.Aliases, opts.KeepAliasedKeys)
+		env.mergeAppending(individualEnv, opts.AppendKeys)
 	}
+	return
+}
 
-	// Parse the key
-	key = splitString[0]
-	if strings.HasPrefix(key, "export") {
-		key = strings.TrimPrefix(key, "export")
+// keyOrigin is the file a key was first read from, and its value there
+type keyOrigin struct {
+	filename string
+	value    string
+}
+
+// checkCrossFileConflicts errors if env, read from filename, sets a key in
+// origins to something else, and adds its keys to origins otherwise
+func checkCrossFileConflicts(origins map[string]keyOrigin, filename string, env *orderedEnv) error {
+	for _, key := range env.keys {
+		value := env.values[key]
+		origin, seen := origins[key]
+		if !seen {
+			origins[key] = keyOrigin{filename: filename, value: value}
+		} else if origin.value != value {
+			return fmt.Errorf("%v is %q in %v but %q in %v", key, origin.value, origin.filename, value, filename)
+		}
+	}
+	return nil
+}
+
+func filenamesOrDefault(filenames []string) []string {
+	if len(filenames) == 0 {
+		return []string{".env"}
+	} else {
+		return filenames
 	}
-	key = strings.Trim(key, " ")
+}
 
-	// Parse the value
-	value = splitString[1]
-	// trim
-	value = strings.Trim(value, " ")
+// loadFiles applies each file in turn, returning everything they contained.
+// With ErrorOnCrossFileConflict nothing is applied until every file has been
+// read and checked, so a conflict leaves the environment as it was.
+func loadFiles(filenames []string, opts Options) (env *orderedEnv, err error) {
+	env = newOrderedEnv()
+	origins := make(map[string]keyOrigin)
+
+	var pending []*orderedEnv
+	for _, filename := range filenames {
+		individualEnv, individualErr := readFile(filename, opts, env.values)
+		if individualErr == nil && opts.ErrorOnCrossFileConflict {
+			individualErr = checkCrossFileConflicts(origins, filename, individualEnv)
+		}
+		if individualErr != nil {
+			err = individualErr
+			return // return early on a spazout
+		}
 
-	// check if we've got quoted values
-	if strings.Count(value, "\"") == 2 || strings.Count(value, "'") == 2 {
-		// pull the quotes off the edges
-		value = strings.Trim(value, "\"'")
+		individualEnv = individualEnv.rename(opts.Aliases, opts.KeepAliasedKeys)
+		if opts.ErrorOnCrossFileConflict {
+			pending = append(pending, individualEnv)
+		} else {
+			individualEnv.applyAppending(opts.AppendKeys)
+		}
+		env.mergeAppending(individualEnv, opts.AppendKeys)
+	}
+
+	for _, individualEnv := range pending {
+		individualEnv.applyAppending(opts.AppendKeys)
+	}
+	return
+}
+
+// orderedEnv is parsed env content that remembers the order keys first
+// appeared in, so they're applied in file order rather than map order
+type orderedEnv struct {
+	keys   []string
+	values map[string]string
+}
+
+func newOrderedEnv() *orderedEnv {
+	return &orderedEnv{values: make(map[string]string)}
+}
+
+func (env *orderedEnv) set(key, value string) {
+	if _, ok := env.values[key]; !ok {
+		env.keys = append(env.keys, key)
+	}
+	env.values[key] = value
+}
+
+func (env *orderedEnv) merge(other *orderedEnv) {
+	env.mergeAppending(other, nil)
+}
+
+// mergeAppending is merge, but keys in appendKeys are added on to any value
+// they already have, joined by their separator
+func (env *orderedEnv) mergeAppending(other *orderedEnv, appendKeys map[string]string) {
+	for _, key := range other.keys {
+		value := other.values[key]
+		if separator, ok := appendKeys[key]; ok {
+			value = appendValue(env.values[key], separator, value)
+		}
+		env.set(key, value)
+	}
+}
+
+// rename returns a copy of env with keys in aliases renamed to their alias,
+// keeping the original key too if keepOriginal is set
+func (env *orderedEnv) rename(aliases map[string]string, keepOriginal bool) *orderedEnv {
+	if len(aliases) == 0 {
+		return env
+	}
+	renamed := newOrderedEnv()
+	for _, key := range env.keys {
+		alias, ok := aliases[key]
+		if !ok || keepOriginal {
+			renamed.set(key, env.values[key])
+		}
+		if ok {
+			renamed.set(alias, env.values[key])
+		}
+	}
+	return renamed
+}
+
+// apply sets each value in the env in order, leaving anything already set alone
+func (env *orderedEnv) apply() {
+	env.applyAppending(nil)
+}
+
+// applyAppending is apply, but keys in appendKeys are added on to the value
+// already in the env, joined by their separator
+func (env *orderedEnv) applyAppending(appendKeys map[string]string) {
+	for _, key := range env.keys {
+		value := env.values[key]
+		if separator, ok := appendKeys[key]; ok {
+			os.Setenv(key, appendValue(os.Getenv(key), separator, value))
+			continue
+		}
+		setEnv(key, value, false)
+	}
+}
+
+func appendValue(existing, separator, value string) string {
+	if existing == "" {
+		return value
+	}
+	return existing + separator + value
+}
+
+// unset returns the values that apply would set, those not already in the env
+func (env *orderedEnv) unset() map[string]string {
+	envMap := make(map[string]string)
+	for key, value := range env.values {
+		if _, exists := os.LookupEnv(key); !exists {
+			envMap[key] = value
+		}
+	}
+	return envMap
+}
 
-		// expand quotes
-		value = strings.Replace(value, "\\\"", "\"", -1)
-		// expand newlines
-		value = strings.Replace(value, "\\n", "\n", -1)
+// Apply sets every key in envMap in the environment, in sorted key order. Unless
+// override is true a variable that already exists (even if empty) is left alone,
+// which is how Load treats the values it reads. It reports which keys it set and
+// which it skipped. Handy for config assembled in code rather than read from a file.
+func Apply(envMap map[string]string, override bool) (set []string, skipped []string) {
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
+	for _, key := range keys {
+		if setEnv(key, envMap[key], override) {
+			set = append(set, key)
+		} else {
+			skipped = append(skipped, key)
+		}
+	}
 	return
 }
 
-func isIgnoredLine(line string) bool {
-	trimmedLine := strings.Trim(line, " \n\t")
-	return len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#")
+func setEnv(key, value string, override bool) bool {
+	if _, exists := os.LookupEnv(key); exists && !override {
+		return false
+	}
+	os.Setenv(key, value)
+	return true
+}
+
+// Parse reads an env file from io.Reader, returning a map of keys and values.
+func Parse(r io.Reader) (envMap map[string]string, err error) {
+	return ParseWithOptions(r, Options{})
+}
+
+// ParseBytes is Parse for content already in memory, from embed or the network
+// say, with exactly the same rules
+func ParseBytes(b []byte) (envMap map[string]string, err error) {
+	return Parse(bytes.NewReader(b))
+}
+
+// ParseWithOptions is Parse with control over how the content is read
+func ParseWithOptions(r io.Reader, opts Options) (envMap map[string]string, err error) {
+	env, err := parse(r, opts, nil)
+	if err != nil {
+		return
+	}
+	return env.values, nil
+}
+
+// parse reads env content in order, values can refer to those parsed before
+// them and to the inherited values (from earlier files in a chain)
+func parse(r io.Reader, opts Options, inherited map[string]string) (env *orderedEnv, err error) {
+	return parseLimited(r, opts, inherited, 0)
+}
+
+// parseLimited is parse that stops once limit keys have been found, if limit > 0
+func parseLimited(r io.Reader, opts Options, inherited map[string]string, limit int) (env *orderedEnv, err error) {
+	p, err := newParser(opts, inherited)
+	if err != nil {
+		return
+	}
+	p.limit = limit
+	err = p.parse(r)
+	return p.env, err
+}
+
+// parser holds the state of parsing one lot of env content
+type parser struct {
+	opts    Options
+	env     *orderedEnv
+	visible map[string]string
+	section string
+	// limit stops parsing once that many keys are found, if > 0
+	limit int
+	// collectErrors gathers bad lines into lineErrors instead of stopping at
+	// the first one, including those that would otherwise be skipped quietly
+	collectErrors bool
+	lineErrors    []LineError
+	// raw holds the unexpanded values for ExpandAfterParse, and base what was
+	// visible before the file was parsed
+	raw  map[string]string
+	base map[string]string
+	// onAssign, if set, sees each key as it's assigned, and onSkip each line
+	// passed over as blank, a comment or out of the section
+	onAssign func(a assignment)
+	onSkip   func(lineNumber int, line string)
+	lines    *lineReader
+	// platformIf is the line of the #if block being read, if any, and
+	// platformSkip whether it's for some other platform
+	platformIf   int
+	platformSkip bool
+}
+
+// assignment is a key the parser has set, as onAssign sees it
+type assignment struct {
+	// first and last are the lines it runs over
+	first, last int
+	// text is what it was parsed from, which for a block scalar is only the
+	// header line
+	text       string
+	key, value string
+}
+
+func newParser(opts Options, inherited map[string]string) (*parser, error) {
+	p := &parser{opts: opts, env: newOrderedEnv(), visible: make(map[string]string)}
+
+	// the sources sit underneath anything inherited
+	if len(opts.ExpandSources) > 0 {
+		sourceOpts := opts
+		sourceOpts.ExpandSources = nil
+		sources, err := readFiles(opts.ExpandSources, sourceOpts)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range sources.values {
+			p.visible[key] = value
+		}
+	}
+
+	for key, value := range inherited {
+		p.visible[key] = value
+	}
+
+	if opts.ExpandAfterParse {
+		p.raw = make(map[string]string)
+		p.base = make(map[string]string, len(p.visible))
+		for key, value := range p.visible {
+			p.base[key] = value
+		}
+	}
+	return p, nil
+}
+
+func (p *parser) parse(r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	// utf-16 has to be decoded as a whole before it can be split into lines
+	if prefix, _ := reader.Peek(2); hasUTF16BOM(prefix) {
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		reader = bufio.NewReader(bytes.NewReader(decodeUTF16(content)))
+	}
+
+	tracker := &lastByteReader{r: reader}
+	scanner := bufio.NewScanner(tracker)
+	maxLine := p.opts.MaxLineBytes
+	if maxLine <= 0 {
+		maxLine = defaultMaxLineBytes
+	}
+	scanner.Buffer(nil, maxLine)
+	scanner.Split(scanLines)
+	lines := &lineReader{scanner: scanner}
+	p.lines = lines
+
+	for {
+		lines.mark()
+		fullLine, ok := lines.next()
+		if !ok {
+			break
+		}
+		startLine := lines.number
+
+		if p.opts.PlatformConditionals {
+			skip, err := p.platformDirective(startLine, fullLine)
+			if err != nil {
+				return err
+			}
+			if skip {
+				continue
+			}
+		}
+
+		// a quoted value can carry on over several lines until its closing
+		// quote, but running out of lines first means it was never closed
+		quote, unterminated := p.openQuote(fullLine)
+		for unterminated {
+			line, ok := lines.next()
+			if !ok {
+				break
+			}
+			fullLine += "\n" + line
+			unterminated = closingQuoteIndex(line, quote) < 0
+		}
+
+		if p.skipLine(fullLine) {
+			if p.onSkip != nil {
+				p.onSkip(startLine, fullLine)
+			}
+			continue
+		}
+
+		var err error
+		if unterminated {
+			err = p.assign(startLine, fullLine, "", "", fmt.Errorf("unterminated %c quote", quote))
+		} else if key, indicator, isBlock := parseBlockScalarHeader(fullLine); isBlock {
+			value, blockErr := readBlockScalar(lines, indentation(fullLine), indicator)
+			key, value = p.normalize(key, value)
+			if blockErr == nil {
+				delete(p.raw, key)
+			}
+			err = p.assign(startLine, fullLine, key, value, blockErr)
+		} else {
+			lineOpts := p.opts
+			lineOpts.deferExpansion = p.opts.ExpandAfterParse
+			key, value, lineErr := parseLine(fullLine, p.visible, lineOpts)
+			key, value = p.normalize(key, value)
+			if lineErr == nil && p.opts.OnWarning != nil && hasBareVariable(fullLine, p.opts) {
+				lineErr = p.opts.OnWarning(Warning{
+					Line:     startLine,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf(unquotedDollarMessage, key),
+				})
+			}
+			if lineErr == nil && lineOpts.deferExpansion {
+				p.raw[key] = value
+			}
+			err = p.assign(startLine, fullLine, key, value, lineErr)
+		}
+		if err != nil {
+			return err
+		}
+		if p.limit > 0 && len(p.env.keys) >= p.limit {
+			return p.expandDeferred()
+		}
+	}
+	if err := scanner.Err(); err == bufio.ErrTooLong {
+		return fmt.Errorf("line %d: longer than %d bytes", lines.number+1, maxLine)
+	} else if err != nil {
+		return err
+	}
+
+	if p.platformIf > 0 {
+		return fmt.Errorf("line %d: #if without #endif", p.platformIf)
+	}
+	if p.opts.RequireFinalNewline && tracker.read && tracker.last != '\n' && tracker.last != '\r' {
+		return errors.New("missing final newline")
+	}
+	if p.opts.ErrorOnEmpty && len(p.env.keys) == 0 && len(p.lineErrors) == 0 {
+		return errors.New("no assignments found")
+	}
+	return p.expandDeferred()
+}
+
+// normalize applies NormalizeKeys and NormalizeValues
+func (p *parser) normalize(key, value string) (string, string) {
+	if p.opts.NormalizeKeys != nil {
+		key = p.opts.NormalizeKeys(key)
+	}
+	if p.opts.NormalizeValues != nil {
+		value = p.opts.NormalizeValues(value)
+	}
+	return key, value
+}
+
+// expandDeferred expands the values left raw for ExpandAfterParse. Each value
+// is expanded when it's first referred to, so references can point anywhere in
+// the file. A key referring to itself, as in PATH=$PATH:/more, sees the value
+// from outside the file instead.
+func (p *parser) expandDeferred() error {
+	resolved := make(map[string]string)
+	resolving := make(map[string]bool)
+	var cycleErr error
+
+	var resolve func(key string) (string, error)
+	resolve = func(key string) (string, error) {
+		if value, ok := resolved[key]; ok {
+			return value, nil
+		}
+		resolving[key] = true
+		defer delete(resolving, key)
+
+		keyOpts := p.opts
+		keyOpts.lookup = func(name string) (string, bool) {
+			if name == key {
+				return "", false
+			}
+			if _, isRaw := p.raw[name]; !isRaw {
+				value, ok := p.env.values[name]
+				return value, ok
+			}
+			if resolving[name] {
+				if cycleErr == nil {
+					cycleErr = fmt.Errorf("reference cycle between %v and %v", key, name)
+				}
+				return "", true
+			}
+			value, err := resolve(name)
+			if err != nil && cycleErr == nil {
+				cycleErr = err
+			}
+			return value, true
+		}
+
+		value, err := parseValue(p.raw[key], p.base, keyOpts)
+		if err != nil {
+			return "", err
+		}
+		resolved[key] = value
+		return value, nil
+	}
+
+	for _, key := range p.env.keys {
+		if _, isRaw := p.raw[key]; !isRaw {
+			continue
+		}
+		value, err := resolve(key)
+		if err == nil {
+			err = cycleErr
+		}
+		if err != nil {
+			return fmt.Errorf("expanding %v: %v", key, err)
+		}
+		_, value = p.normalize(key, value)
+		p.env.values[key] = value
+		p.visible[key] = value
+	}
+	return nil
+}
+
+// the longest line the parser will take unless MaxLineBytes says otherwise
+const defaultMaxLineBytes = 1 << 20
+
+// scanLines is bufio.ScanLines, but a lone \r ends a line too (as old Mac
+// files have it), not just \n and \r\n
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		// need to see whether a \n follows
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// splitLines splits content into lines by the same rules as the parser, so
+// line N of a parse error is always lines[N-1]
+func splitLines(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		advance, token, _ := scanLines(content, true)
+		lines = append(lines, string(token))
+		content = content[advance:]
+	}
+	return lines
+}
+
+// lineReader hands out the scanned lines one at a time, counting them, and can
+// take the last one back for when it's read a line too far. It keeps the lines
+// handed out since the last mark, which are what an assignment was read from.
+type lineReader struct {
+	scanner   *bufio.Scanner
+	number    int
+	unread    string
+	hasUnread bool
+	marked    []string
+}
+
+func (l *lineReader) next() (string, bool) {
+	if l.hasUnread {
+		l.hasUnread = false
+		l.number++
+		l.marked = append(l.marked, l.unread)
+		return l.unread, true
+	}
+	if !l.scanner.Scan() {
+		return "", false
+	}
+	l.number++
+	l.marked = append(l.marked, l.scanner.Text())
+	return l.scanner.Text(), true
+}
+
+func (l *lineReader) unreadLine(line string) {
+	l.unread, l.hasUnread = line, true
+	l.number--
+	l.marked = l.marked[:len(l.marked)-1]
+}
+
+// mark forgets the lines handed out so far
+func (l *lineReader) mark() {
+	l.marked = l.marked[:0]
+}
+
+// lastByteReader remembers the last byte read through it, so the parser can
+// tell whether the content ended with a newline without holding all of it
+type lastByteReader struct {
+	r    io.Reader
+	read bool
+	last byte
+}
+
+func (l *lastByteReader) Read(b []byte) (int, error) {
+	n, err := l.r.Read(b)
+	if n > 0 {
+		l.read = true
+		l.last = b[n-1]
+	}
+	return n, err
+}
+
+// syntaxOnly is opts with every reference looking set to something, so
+// checking a file doesn't depend on the environment it happens to be checked
+// in: ${API_KEY:?required} is fine whether or not API_KEY is set
+func syntaxOnly(opts Options) Options {
+	opts.lookup = func(name string) (string, bool) { return "set", true }
+	return opts
+}
+
+// isQuote says whether c acts as a quote, per QuoteChars
+func (opts Options) isQuote(c byte) bool {
+	if c != '"' && c != '\'' {
+		return false
+	}
+	if opts.QuoteChars == "" {
+		return true
+	}
+	return strings.IndexByte(opts.QuoteChars, c) >= 0
+}
+
+// openQuote reports whether line is an assignment whose quoted value isn't
+// closed by the end of the line, and which quote it's waiting on
+func (p *parser) openQuote(line string) (quote byte, open bool) {
+	if isIgnoredLine(line) || isCommentLine(line, p.opts.CommentPrefixes) {
+		return
+	}
+	_, value, ok := p.opts.splitKeyValue(line)
+	if !ok {
+		return
+	}
+	value = strings.TrimLeft(value, " \t")
+	if len(value) == 0 || !p.opts.isQuote(value[0]) {
+		return
+	}
+	quote = value[0]
+	return quote, closingQuoteIndex(value[1:], quote) < 0
+}
+
+// closingQuoteIndex finds quote in s, or -1. Double quotes can be escaped,
+// single quotes are literal all the way to the next one.
+func closingQuoteIndex(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// skipLine says whether fullLine holds nothing to parse, keeping track of
+// which section it's in along the way
+func (p *parser) skipLine(fullLine string) bool {
+	if p.opts.Section != "" {
+		if section, isHeader := parseSectionHeader(fullLine); isHeader {
+			p.section = section
+			return true
+		}
+		if p.section != "" && p.section != p.opts.Section {
+			return true
+		}
+	}
+
+	return isIgnoredLine(fullLine) || isCommentLine(fullLine, p.opts.CommentPrefixes)
+}
+
+// platformDirective handles the #if and #endif lines of PlatformConditionals,
+// reporting whether line should be skipped: either it's a directive or it's in
+// a block for another platform. Only #if GOOS= and #if GOARCH= are directives,
+// any other #if is just a comment.
+func (p *parser) platformDirective(lineNumber int, line string) (skip bool, err error) {
+	directive := strings.TrimSpace(line)
+	name, want, isCondition := "", "", false
+	if strings.HasPrefix(directive, "#if ") {
+		name, want, isCondition = splitKeyValue(strings.TrimSpace(directive[len("#if "):]))
+		name, want = strings.TrimSpace(name), strings.TrimSpace(want)
+		isCondition = isCondition && (name == "GOOS" || name == "GOARCH")
+	}
+
+	switch {
+	case isCondition:
+		if p.platformIf > 0 {
+			return false, fmt.Errorf("line %d: #if inside the #if on line %d, they can't be nested", lineNumber, p.platformIf)
+		}
+		actual := runtime.GOOS
+		if name == "GOARCH" {
+			actual = runtime.GOARCH
+		}
+		p.platformIf, p.platformSkip = lineNumber, actual != want
+		return true, nil
+	case directive == "#endif":
+		if p.platformIf == 0 {
+			return false, fmt.Errorf("line %d: #endif without #if", lineNumber)
+		}
+		p.platformIf, p.platformSkip = 0, false
+		return true, nil
+	}
+	return p.platformSkip, nil
+}
+
+// assign records the outcome of parsing the assignment starting on lineNumber
+func (p *parser) assign(lineNumber int, fullLine, key, value string, lineErr error) error {
+	if lineErr == nil && p.opts.MaxValueBytes > 0 && len(value) > p.opts.MaxValueBytes {
+		lineErr = fmt.Errorf("value of %v is %d bytes, longer than %d", key, len(value), p.opts.MaxValueBytes)
+	}
+	if lineErr == nil && p.opts.RejectControlChars {
+		lineErr = controlCharError(key, lineNumber, p.lines.marked)
+	}
+
+	switch {
+	case lineErr == nil:
+		p.env.set(key, value)
+		p.visible[key] = value
+		if p.onAssign != nil {
+			p.onAssign(assignment{first: lineNumber, last: p.lines.number, text: fullLine, key: key, value: value})
+		}
+		return nil
+	case p.collectErrors:
+		p.lineErrors = append(p.lineErrors, LineError{Line: lineNumber, Content: fullLine, Err: lineErr})
+		return nil
+	case lineErr == errNoSeparator:
+		// not an assignment, skip it
+		return nil
+	case lineErr == errEmptyKey:
+		// an empty key can't be set, so unlike other bad lines don't skip it quietly
+		return fmt.Errorf("empty key on line %d", lineNumber)
+	}
+	return fmt.Errorf("line %d: %v", lineNumber, lineErr)
+}
+
+// LineError is a problem with one line of an env file
+type LineError struct {
+	// Line is the 1-based line number
+	Line int
+	// Content is the line as it appears in the file
+	Content string
+	Err     error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Content)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// hasUTF16BOM reports whether prefix starts with a UTF-16 byte order mark
+func hasUTF16BOM(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte{0xFF, 0xFE}) || bytes.HasPrefix(prefix, []byte{0xFE, 0xFF})
+}
+
+// decodeUTF16 transcodes content starting with a UTF-16 byte order mark (as
+// PowerShell writes by default) to UTF-8, anything else is assumed to be UTF-8
+func decodeUTF16(content []byte) []byte {
+	var byteOrder binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		byteOrder = binary.LittleEndian
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		byteOrder = binary.BigEndian
+	default:
+		return content
+	}
+
+	units := make([]uint16, (len(content)-2)/2)
+	for i := range units {
+		units[i] = byteOrder.Uint16(content[2+2*i:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// checkPermissions fails for a file that anyone but its owner can access, the
+// way ssh refuses loose private keys. Windows doesn't have the permission bits
+// so anything goes there.
+func checkPermissions(file *os.File) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("permissions %#o for %q are too open, it should only be accessible by its owner", perm, file.Name())
+	}
+	return nil
+}
+
+func readFile(filename string, opts Options, inherited map[string]string) (env *orderedEnv, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if opts.RequireSecurePermissions {
+		if err = checkPermissions(file); err != nil {
+			return
+		}
+	}
+
+	opts.baseDir = filepath.Dir(filename)
+
+	// the os errors already name the file, but parse errors only know the line
+	env, err = parse(file, opts, inherited)
+	if err != nil {
+		err = fmt.Errorf("parsing %q: %w", filename, err)
+	}
+	return
+}
+
+func parseLine(line string, envMap map[string]string, opts Options) (key string, value string, err error) {
+	if len(line) == 0 {
+		err = errors.New("zero length string")
+		return
+	}
+
+	for _, prefix := range opts.CommentPrefixes {
+		line = stripComment(line, prefix, true, opts)
+	}
+
+	// ditch the comments (but keep quoted hashes)
+	line = stripComment(line, "#", false, opts)
+
+	// a quoted key runs to its closing quote, whatever it contains
+	if opts.QuotedKeys {
+		var rawValue string
+		var isQuoted bool
+		if key, rawValue, isQuoted, err = splitQuotedKey(line); isQuoted {
+			if err == nil {
+				value, err = parseValue(rawValue, envMap, opts)
+			}
+			return
+		}
+	}
+
+	// now split key from value
+	rawKey, rawValue, ok := opts.splitKeyValue(line)
+	if !ok {
+		err = errNoSeparator
+		return
+	}
+
+	// Parse the key
+	// comments are already gone, so all that's left to drop is the export
+	key = strings.Trim(rawKey, " \t")
+	if strings.HasPrefix(key, "export ") || strings.HasPrefix(key, "export\t") {
+		key = strings.TrimPrefix(key, "export")
+	}
+	key = strings.Trim(key, " \t")
+	if len(key) == 0 {
+		err = errEmptyKey
+		return
+	}
+	key = keyUnescaper.Replace(key)
+
+	// Parse the value
+	value, err = parseValue(rawValue, envMap, opts)
+
+	return
+}
+
+// splitKeyValue splits the line on the first = (or failing that the first yaml
+// style :) that isn't escaped with a backslash, so keys can contain them as \= and \:.
+// Keys can't contain quotes either, so the search stops at the first one, which
+// leaves separators inside a quoted value alone, as in KEY: "a=b".
+func splitKeyValue(line string) (key, value string, ok bool) {
+	for _, separator := range []byte{'=', ':'} {
+		for i := 0; i < len(line) && line[i] != '"' && line[i] != '\''; i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] == separator {
+				return line[:i], line[i+1:], true
+			}
+		}
+	}
+	return
+}
+
+// splitKeyValue is the package splitKeyValue, unless Separators are set
+func (opts Options) splitKeyValue(line string) (key, value string, ok bool) {
+	if len(opts.Separators) == 0 {
+		return splitKeyValue(line)
+	}
+	for i := 0; i < len(line) && line[i] != '"' && line[i] != '\''; i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		for _, separator := range opts.Separators {
+			if separator != "" && strings.HasPrefix(line[i:], separator) {
+				return line[:i], line[i+len(separator):], true
+			}
+		}
+	}
+	return
+}
+
+// splitQuotedKey handles lines like "a=b"=c where the key is wrapped in quotes,
+// reporting isQuoted false when the key isn't quoted at all
+func splitQuotedKey(line string) (key, value string, isQuoted bool, err error) {
+	line = strings.TrimLeft(line, " ")
+	if strings.HasPrefix(line, "export ") {
+		line = strings.TrimLeft(strings.TrimPrefix(line, "export"), " ")
+	}
+	if len(line) == 0 || line[0] != '"' && line[0] != '\'' {
+		return
+	}
+
+	end := strings.IndexByte(line[1:], line[0])
+	if end < 0 {
+		return
+	}
+	key = line[1 : end+1]
+	isQuoted = true
+
+	rest := strings.TrimLeft(line[end+2:], " ")
+	if len(rest) == 0 || rest[0] != '=' && rest[0] != ':' {
+		err = errNoSeparator
+		return
+	}
+	if len(key) == 0 {
+		err = errEmptyKey
+		return
+	}
+	value = rest[1:]
+	return
+}
+
+var (
+	keyUnescaper = strings.NewReplacer(`\=`, "=", `\:`, ":")
+	keyEscaper   = strings.NewReplacer("=", `\=`, ":", `\:`)
+)
+
+func parseValue(value string, envMap map[string]string, opts Options) (string, error) {
+	// trim, remembering the trailing space for PreserveTrailingSpace
+	value = strings.TrimLeft(value, " \t")
+	untrimmed := value
+	value = strings.TrimRight(value, " \t")
+
+	if opts.KeepQuotes {
+		return value, nil
+	}
+	if opts.deferExpansion {
+		// this gets parsed again once everything is read
+		if opts.PreserveTrailingSpace {
+			return untrimmed, nil
+		}
+		return value, nil
+	}
+
+	// single quoted values are taken literally, no escapes and no expansion
+	if opts.isQuote('\'') && strings.HasPrefix(value, "'") && strings.Count(value, "'") == 2 {
+		return strings.Trim(value, "'"), nil
+	}
+
+	// double quoted values get their escapes processed as well as expansion,
+	// escaped quotes inside them are fine as long as the value is wrapped
+	if opts.isQuote('"') {
+		if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			return expandVariables(value[1:len(value)-1], envMap, true, opts)
+		}
+		if strings.Count(value, "\"") == 2 {
+			return expandVariables(strings.Trim(value, "\""), envMap, true, opts)
+		}
+	}
+
+	// anything left is unquoted
+	if opts.PreserveTrailingSpace {
+		value = untrimmed
+	}
+
+	if opts.AllowFileReferences {
+		if strings.HasPrefix(value, "@") {
+			return readFileReference(value[1:], envMap, opts)
+		}
+		if strings.HasPrefix(value, "\\@") {
+			value = value[1:]
+		}
+	}
+
+	return expandVariables(value, envMap, opts.ExpandUnquotedEscapes, opts)
+}
+
+// readFileReference gives the trimmed content of the file an @ value names,
+// which can use references itself
+func readFileReference(path string, envMap map[string]string, opts Options) (string, error) {
+	path, err := expandVariables(path, envMap, false, opts)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(opts.baseDir, path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("file reference: %v", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// indexControlChar finds the first raw control character in s other than tab
+// and newline, or -1
+// controlCharError reports the first raw control character in lines, the
+// lines an assignment to key was read from starting at lineNumber
+func controlCharError(key string, lineNumber int, lines []string) error {
+	for n, line := range lines {
+		i := indexControlChar(line)
+		switch {
+		case i < 0:
+			continue
+		case n == 0:
+			return fmt.Errorf("%v has control character %q at column %d", key, line[i], i+1)
+		}
+		return fmt.Errorf("%v has control character %q on line %d at column %d", key, line[i], lineNumber+n, i+1)
+	}
+	return nil
+}
+
+func indexControlChar(s string) int {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 0x20 && s[i] != '\t' && s[i] != '\n') || s[i] == 0x7f {
+			return i
+		}
+	}
+	return -1
+}
+
+func isCommentLine(line string, prefixes []string) bool {
+	trimmedLine := strings.Trim(line, " \t")
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(trimmedLine, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripComment cuts the line at the first prefix that isn't inside quotes,
+// and when afterSpace is set follows whitespace. Single and double quotes are
+// tracked separately, so each can hold the other, and only double quotes can
+// have a quote escaped inside them.
+func stripComment(line, prefix string, afterSpace bool, opts Options) string {
+	var openQuote byte
+	for i := 0; i < len(line); i++ {
+		char := line[i]
+		switch {
+		case openQuote != 0:
+			if char == '\\' && openQuote == '"' {
+				i++
+			} else if char == openQuote {
+				openQuote = 0
+			}
+		case opts.isQuote(char):
+			openQuote = char
+		case strings.HasPrefix(line[i:], prefix) && (!afterSpace || i > 0 && (line[i-1] == ' ' || line[i-1] == '\t')):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseSectionHeader(line string) (section string, isHeader bool) {
+	trimmedLine := strings.Trim(line, " \t")
+	if len(trimmedLine) < 2 || trimmedLine[0] != '[' || trimmedLine[len(trimmedLine)-1] != ']' {
+		return
+	}
+	return strings.Trim(trimmedLine[1:len(trimmedLine)-1], " "), true
+}
+
+func isIgnoredLine(line string) bool {
+	trimmedLine := strings.Trim(line, " \n\t")
+	return len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#")
+}
+
+// a legal prefix is the start of a legal key: a letter or underscore
+// followed by letters, digits, underscores or dots
+func isValidKeyPrefix(prefix string) bool {
+	for i, char := range prefix {
+		switch {
+		case char == '_', char >= 'A' && char <= 'Z', char >= 'a' && char <= 'z':
+		case i > 0 && (char == '.' || char >= '0' && char <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// QuoteValue gives the shortest way to write s as a .env value, for building
+// lines by hand: s as it is when it's plain enough, such as 8080 or
+// /usr/local/bin, and double quoted with escapes otherwise. Parsing "X=" +
+// QuoteValue(s) with the standard rules always gives back s.
+func QuoteValue(s string) string {
+	if isSafeUnquoted(s) {
+		return s
+	}
+	return `"` + doubleQuoteEscape(s) + `"`
+}
+
+// isSafeUnquoted reports whether s can be written without quotes, which is
+// only for letters, digits and a few harmless punctuation marks
+func isSafeUnquoted(s string) bool {
+	for _, char := range s {
+		switch {
+		case unicode.IsLetter(char), unicode.IsDigit(char):
+		case strings.ContainsRune("_-./:,+", char):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func doubleQuoteEscape(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	value = strings.Replace(value, "\"", "\\\"", -1)
+	value = strings.Replace(value, "$", "\\$", -1)
+	value = strings.Replace(value, "\n", "\\n", -1)
+	value = strings.Replace(value, "\r", "\\r", -1)
+	return value
 }
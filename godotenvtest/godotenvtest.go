@@ -0,0 +1,61 @@
+// Package godotenvtest loads env files for the length of a test, keeping the
+// testing import out of godotenv itself. Import it as
+//
+//	import "github.com/joho/godotenv/godotenvtest"
+package godotenvtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+// LoadForTest loads the files (or .env) as godotenv.Load does and puts back
+// what it changed when the test and its subtests finish: keys the files set
+// that weren't there before are unset again, and any whose value the load
+// changed get their old value back. Everything else, including what the test
+// itself does to keys that were already set, is left alone. Failing to load
+// fails the test with tb.Fatal.
+//
+// As with anything using os.Setenv, don't use it in parallel tests.
+func LoadForTest(tb testing.TB, filenames ...string) {
+	tb.Helper()
+
+	before := environ()
+	err := godotenv.Load(filenames...)
+
+	// only what the load itself changed is put back, and that's worked out
+	// from the environment so a load failing partway is covered too
+	changed := make(map[string]*string)
+	for key, current := range environ() {
+		switch value, wasSet := before[key]; {
+		case !wasSet:
+			changed[key] = nil
+		case current != value:
+			changed[key] = &value
+		}
+	}
+
+	tb.Cleanup(func() {
+		for key, value := range changed {
+			if value == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *value)
+			}
+		}
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, value := godotenv.SplitEntry(entry)
+		env[key] = value
+	}
+	return env
+}
@@ -0,0 +1,62 @@
+package godotenvtest
+
+import (
+	"os"
+	"testing"
+)
+
+// fatalRecorder stands in for a test that's expected to fail
+type fatalRecorder struct {
+	testing.TB
+	failed   bool
+	cleanups []func()
+}
+
+func (r *fatalRecorder) Helper()                   {}
+func (r *fatalRecorder) Fatal(args ...interface{}) { r.failed = true }
+func (r *fatalRecorder) Cleanup(cleanup func())    { r.cleanups = append(r.cleanups, cleanup) }
+
+func TestLoadForTest(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "from before")
+	os.Setenv("UNRELATED", "untouched")
+
+	t.Run("loads", func(t *testing.T) {
+		LoadForTest(t, "../fixtures/plain.env")
+
+		if os.Getenv("OPTION_B") != "2" {
+			t.Errorf("Expected OPTION_B to be loaded, got '%v'", os.Getenv("OPTION_B"))
+		}
+		if os.Getenv("OPTION_A") != "from before" {
+			t.Errorf("Expected OPTION_A not to be overridden, got '%v'", os.Getenv("OPTION_A"))
+		}
+		os.Setenv("OPTION_A", "changed by the test")
+	})
+
+	if _, ok := os.LookupEnv("OPTION_B"); ok {
+		t.Error("Expected OPTION_B to be unset after the test")
+	}
+	if os.Getenv("OPTION_A") != "changed by the test" {
+		t.Errorf("Expected the test's own change to OPTION_A kept, got '%v'", os.Getenv("OPTION_A"))
+	}
+	if os.Getenv("UNRELATED") != "untouched" {
+		t.Errorf("Expected UNRELATED untouched, got '%v'", os.Getenv("UNRELATED"))
+	}
+}
+
+func TestLoadForTestPartialFailure(t *testing.T) {
+	os.Clearenv()
+
+	recorder := &fatalRecorder{TB: t}
+	LoadForTest(recorder, "../fixtures/plain.env", "somefilethatwillneverexistever.env")
+	if !recorder.failed {
+		t.Error("Expected the missing file to fail the test")
+	}
+	for _, cleanup := range recorder.cleanups {
+		cleanup()
+	}
+
+	if len(os.Environ()) != 0 {
+		t.Errorf("Expected everything the first file set to be unset, got %v", os.Environ())
+	}
+}
@@ -0,0 +1,104 @@
+package godotenv
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadINI reads the top level keys of a simple INI or TOML file into an env
+// map, so the same config pipeline can take either. Only the flat subset is
+// understood: key = value lines, with ; or # comments. Values can be bare,
+// "double quoted" with TOML (or Go) escapes, or 'single quoted' literally,
+// and numbers and booleans come out as written. [sections] or [tables],
+// arrays, inline tables and multi-line strings are errors, since they have
+// no flat env equivalent. Unlike Read nothing is left out for already being
+// in the environment.
+func ReadINI(filename string) (envMap map[string]string, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	envMap = make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, defaultMaxLineBytes)
+	scanner.Split(scanLines)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			return nil, fmt.Errorf("%v:%d: can't flatten table %v", filename, lineNumber, line)
+		}
+
+		equals := strings.IndexByte(line, '=')
+		if equals < 0 {
+			return nil, fmt.Errorf("%v:%d: no = in %q", filename, lineNumber, line)
+		}
+		key := strings.TrimSpace(line[:equals])
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+		if key == "" {
+			return nil, fmt.Errorf("%v:%d: %v", filename, lineNumber, errEmptyKey)
+		}
+
+		value, err := parseINIValue(strings.TrimSpace(line[equals+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%v:%d: %v: %v", filename, lineNumber, key, err)
+		}
+		envMap[key] = value
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// parseINIValue turns everything after the = into a string, dropping any
+// trailing comment
+func parseINIValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"""`), strings.HasPrefix(value, "'''"):
+		return "", fmt.Errorf("can't read multi-line string")
+	case strings.HasPrefix(value, "["), strings.HasPrefix(value, "{"):
+		return "", fmt.Errorf("can't flatten %v", value)
+	case strings.HasPrefix(value, `"`):
+		end := 1
+		for ; end < len(value) && value[end] != '"'; end++ {
+			if value[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(value) {
+			return "", fmt.Errorf("unterminated \" quote")
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != '#' && rest[0] != ';' {
+			return "", fmt.Errorf("unexpected %q after the value", rest)
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ' quote")
+		}
+		if rest := strings.TrimSpace(value[end+2:]); rest != "" && rest[0] != '#' && rest[0] != ';' {
+			return "", fmt.Errorf("unexpected %q after the value", rest)
+		}
+		return value[1 : end+1], nil
+	}
+
+	// a bare value runs to a comment that follows whitespace
+	for i := 1; i < len(value); i++ {
+		if (value[i] == '#' || value[i] == ';') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(value), nil
+}
@@ -0,0 +1,45 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadINI(t *testing.T) {
+	envMap, err := ReadINI("fixtures/flat.toml")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"title":      `TOML "Example"`,
+		"path":       `C:\Users\me`,
+		"port":       "8080",
+		"ratio":      "0.75",
+		"enabled":    "true",
+		"quoted key": "tab\there",
+		"greeting":   "hello world",
+		"empty":      "",
+	}
+	if len(envMap) != len(expectedValues) {
+		t.Errorf("Expected %d keys got %q", len(expectedValues), envMap)
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+}
+
+func TestReadININested(t *testing.T) {
+	_, err := ReadINI("fixtures/nested.toml")
+	if err == nil || !strings.Contains(err.Error(), "fixtures/nested.toml:3: can't flatten table [database]") {
+		t.Errorf("Expected a table error, got %v", err)
+	}
+
+	for _, line := range []string{`ports = [80, 443]`, `point = { x = 1 }`, `text = """`, `broken = "open`} {
+		if _, err := parseINIValue(strings.TrimSpace(line[strings.IndexByte(line, '=')+1:])); err == nil {
+			t.Errorf("Expected an error for %v", line)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package godotenv
+
+import (
+	"os"
+	"regexp"
+	"sync"
+)
+
+// LazyConfig holds values read from env files where those matching a pattern,
+// like "${vault:secret/db}", are tokens only resolved the first time they're
+// asked for. That saves fetching secrets the app never reads. Resolved values
+// are cached, and tokens (resolved or not) are never written to the process
+// environment. It's safe for concurrent use.
+type LazyConfig struct {
+	values   map[string]string
+	tokens   map[string]*lazyValue
+	resolver func(token string) (string, error)
+}
+
+type lazyValue struct {
+	mu       sync.Mutex
+	token    string
+	value    string
+	resolved bool
+}
+
+// NewLazyConfig makes a LazyConfig from envMap, as returned by Read or Parse,
+// where values that pattern matches are tokens for resolver. Note a quoted
+// value is needed for tokens containing #, or the rest is a comment.
+func NewLazyConfig(envMap map[string]string, pattern *regexp.Regexp, resolver func(token string) (string, error)) *LazyConfig {
+	c := &LazyConfig{
+		values:   make(map[string]string),
+		tokens:   make(map[string]*lazyValue),
+		resolver: resolver,
+	}
+	for key, value := range envMap {
+		if pattern.MatchString(value) {
+			c.tokens[key] = &lazyValue{token: value}
+		} else {
+			c.values[key] = value
+		}
+	}
+	return c
+}
+
+// ReadLazy is Read into a LazyConfig, see NewLazyConfig
+func ReadLazy(pattern *regexp.Regexp, resolver func(token string) (string, error), filenames ...string) (*LazyConfig, error) {
+	envMap, err := Read(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLazyConfig(envMap, pattern, resolver), nil
+}
+
+// LoadLazy is ReadLazy that also sets the values that aren't tokens in the
+// environment, as Load would. Tokens are left out of the environment entirely,
+// get them through the LazyConfig.
+func LoadLazy(pattern *regexp.Regexp, resolver func(token string) (string, error), filenames ...string) (*LazyConfig, error) {
+	c, err := ReadLazy(pattern, resolver, filenames...)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.values {
+		if err := os.Setenv(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Get returns the value for key, resolving it first if it's a token. A
+// resolver error is returned as is and not cached, so the next Get tries again.
+// Keys that were never read give "".
+func (c *LazyConfig) Get(key string) (string, error) {
+	lazy, ok := c.tokens[key]
+	if !ok {
+		return c.values[key], nil
+	}
+
+	// each token has its own lock, so a slow fetch only holds up Gets of
+	// the same key
+	lazy.mu.Lock()
+	defer lazy.mu.Unlock()
+	if !lazy.resolved {
+		value, err := c.resolver(lazy.token)
+		if err != nil {
+			return "", err
+		}
+		lazy.value, lazy.resolved = value, true
+	}
+	return lazy.value, nil
+}
@@ -0,0 +1,79 @@
+package godotenv
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+var vaultToken = regexp.MustCompile(`^\$\{vault:.*\}$`)
+
+func TestLoadLazy(t *testing.T) {
+	os.Clearenv()
+
+	var mu sync.Mutex
+	fetched := make(map[string]int)
+	resolver := func(token string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched[token]++
+		return "resolved " + token, nil
+	}
+
+	config, err := LoadLazy(vaultToken, resolver, "fixtures/lazy.env")
+	if err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	if os.Getenv("APP_NAME") != "my app" {
+		t.Errorf("Expected plain values to be loaded, got '%v'", os.Getenv("APP_NAME"))
+	}
+	if _, ok := os.LookupEnv("DB_PASSWORD"); ok {
+		t.Error("Expected tokens to stay out of the env")
+	}
+	if len(fetched) != 0 {
+		t.Errorf("Expected nothing resolved before Get, got %v", fetched)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := config.Get("DB_PASSWORD")
+			if err != nil || value != "resolved ${vault:secret/db#password}" {
+				t.Errorf("Unexpected %q %v", value, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetched["${vault:secret/db#password}"] != 1 || len(fetched) != 1 {
+		t.Errorf("Expected DB_PASSWORD resolved exactly once and API_KEY never, got %v", fetched)
+	}
+	if _, ok := os.LookupEnv("DB_PASSWORD"); ok {
+		t.Error("Expected resolved tokens to stay out of the env")
+	}
+	if value, err := config.Get("APP_NAME"); err != nil || value != "my app" {
+		t.Errorf("Unexpected %q %v", value, err)
+	}
+}
+
+func TestLazyConfigResolverError(t *testing.T) {
+	calls := 0
+	config := NewLazyConfig(map[string]string{"SECRET": "${vault:flaky}"}, vaultToken, func(token string) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("vault unavailable")
+		}
+		return "finally", nil
+	})
+
+	if _, err := config.Get("SECRET"); err == nil || err.Error() != "vault unavailable" {
+		t.Errorf("Expected the resolver error, got %v", err)
+	}
+	if value, err := config.Get("SECRET"); err != nil || value != "finally" {
+		t.Errorf("Expected a retry after the error, got %q %v", value, err)
+	}
+}
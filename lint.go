@@ -0,0 +1,215 @@
+package godotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Severity says how serious a lint Warning is
+type Severity int
+
+const (
+	// SeverityWarning is for style problems and likely mistakes
+	SeverityWarning Severity = iota
+	// SeverityError is for lines that won't load the way they read
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Warning is a single problem found by Lint
+type Warning struct {
+	Line     int
+	Severity Severity
+	Message  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %v: %v", w.Line, w.Severity, w.Message)
+}
+
+// Lint reads an env file from io.Reader and reports style and correctness
+// problems with it, in line order. It only parses, nothing is applied to the
+// environment. The error is only for failing to read r, problems with the
+// content itself come back as warnings.
+func Lint(r io.Reader) (warnings []Warning, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	content = decodeUTF16(content)
+
+	warn := func(line int, severity Severity, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	checkTrailing := func(lineNumber int, text string) {
+		if strings.TrimRight(text, " \t") != text {
+			warn(lineNumber, SeverityWarning, "trailing whitespace")
+		}
+	}
+
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return
+	}
+	p.collectErrors = true
+	p.onSkip = checkTrailing
+	firstSeen := make(map[string]int)
+	p.onAssign = func(a assignment) {
+		lineNumber, key := a.first, a.key
+		checkTrailing(lineNumber, a.text)
+
+		if !isPosixKey(key) {
+			warn(lineNumber, SeverityWarning, "key %v isn't a portable variable name ([A-Za-z_][A-Za-z0-9_]*)", key)
+		}
+
+		if previous, ok := firstSeen[key]; ok {
+			warn(lineNumber, SeverityWarning, "duplicate key %v, first set on line %d", key, previous)
+		} else {
+			firstSeen[key] = lineNumber
+		}
+
+		if hasBareVariable(a.text, Options{}) {
+			warn(lineNumber, SeverityWarning, unquotedDollarMessage, key)
+		}
+
+		_, rawValue, _ := splitKeyValue(a.text)
+		rawValue = strings.Trim(rawValue, " \t")
+		if strings.HasPrefix(rawValue, "\"") || strings.HasPrefix(rawValue, "'") {
+			return
+		}
+		if hash := strings.Index(rawValue, "#"); hash > 0 {
+			if !strings.ContainsAny(rawValue[hash-1:hash], " \t") {
+				warn(lineNumber, SeverityError, "unquoted value for %v contains #, everything after it is dropped as a comment", key)
+			}
+			rawValue = strings.TrimRight(rawValue[:hash], " \t")
+		}
+		if strings.ContainsAny(rawValue, " \t") {
+			warn(lineNumber, SeverityWarning, "unquoted value for %v contains whitespace", key)
+		}
+	}
+	if err = p.parse(bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	for _, lineError := range p.lineErrors {
+		checkTrailing(lineError.Line, lineError.Content)
+		warn(lineError.Line, SeverityError, "can't parse line: %v", lineError.Err)
+	}
+
+	if len(content) > 0 && content[len(content)-1] != '\n' && content[len(content)-1] != '\r' {
+		warn(len(splitLines(content)), SeverityWarning, "missing final newline")
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	return
+}
+
+const unquotedDollarMessage = "unquoted value for %v contains a bare $ reference, which is expanded; single quote it or write \\$ if it's meant literally"
+
+// hasBareVariable reports whether the value on line is unquoted and has a $VAR
+// reference in it. Those are easily written by accident, in a password say,
+// where ${VAR} rarely is. An escaped \$ is fine.
+func hasBareVariable(line string, opts Options) bool {
+	if opts.KeepQuotes || opts.ExpansionDelims.Open != "" {
+		return false
+	}
+
+	_, rawValue, ok := opts.splitKeyValue(stripComment(line, "#", false, opts))
+	rawValue = strings.TrimLeft(rawValue, " \t")
+	if !ok || len(rawValue) == 0 || opts.isQuote(rawValue[0]) {
+		return false
+	}
+
+	for i := 0; i < len(rawValue); i++ {
+		switch rawValue[i] {
+		case '\\':
+			i++
+		case '$':
+			rest := rawValue[i+1:]
+			if _, length := scanVariableReference(rest); length > 0 && !strings.HasPrefix(rest, "{") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPosixKey(key string) bool {
+	if len(key) == 0 || key[0] >= '0' && key[0] <= '9' {
+		return false
+	}
+	for _, char := range key {
+		if !isVariableChar(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check strictly parses each file without loading anything: every line that
+// isn't blank or a comment has to parse, and no key can be set twice in the
+// same file. It returns nil when all is well, otherwise a single error listing
+// every problem found as filename:line: message.
+func Check(filenames ...string) error {
+	var problems []string
+
+	for _, filename := range filenamesOrDefault(filenames) {
+		fileProblems, err := checkFile(filename)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// checkFile lists the problems Check finds in one file, in line order
+func checkFile(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return nil, err
+	}
+	p.collectErrors = true
+	lineErrors := make([]LineError, 0)
+	firstSeen := make(map[string]int)
+	p.onAssign = func(a assignment) {
+		if previous, ok := firstSeen[a.key]; ok {
+			lineErrors = append(lineErrors, LineError{Line: a.first, Err: fmt.Errorf("duplicate key %v, first set on line %d", a.key, previous)})
+		} else {
+			firstSeen[a.key] = a.first
+		}
+	}
+	if err := p.parse(file); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	lineErrors = append(lineErrors, p.lineErrors...)
+	sort.SliceStable(lineErrors, func(i, j int) bool { return lineErrors[i].Line < lineErrors[j].Line })
+	problems := make([]string, len(lineErrors))
+	for i, lineError := range lineErrors {
+		problems[i] = fmt.Sprintf("%v:%d: %v", filename, lineError.Line, lineError.Err)
+	}
+	return problems, nil
+}
@@ -0,0 +1,180 @@
+package godotenv
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	file, err := os.Open("fixtures/lint.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	os.Setenv("OPTION_A", "unchanged")
+	warnings, err := Lint(file)
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+
+	expected := []Warning{
+		{3, SeverityWarning, "trailing whitespace"},
+		{3, SeverityWarning, "unquoted value for OPTION_B contains whitespace"},
+		{4, SeverityWarning, "key OPTION.C isn't a portable variable name ([A-Za-z_][A-Za-z0-9_]*)"},
+		{5, SeverityWarning, "duplicate key OPTION_A, first set on line 2"},
+		{6, SeverityError, "unquoted value for PASSWORD contains #, everything after it is dropped as a comment"},
+		{9, SeverityError, "can't parse line: Can't separate key from value"},
+		{11, SeverityWarning, "missing final newline"},
+	}
+
+	if len(warnings) != len(expected) {
+		t.Errorf("Expected %d warnings, got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i := 0; i < len(expected) && i < len(warnings); i++ {
+		if warnings[i] != expected[i] {
+			t.Errorf("Expected warning '%v', got '%v'", expected[i], warnings[i])
+		}
+	}
+
+	if os.Getenv("OPTION_A") != "unchanged" {
+		t.Error("Lint changed the environment")
+	}
+}
+
+func TestLintBlockScalar(t *testing.T) {
+	warnings, err := Lint(strings.NewReader("CERT: |\n  a: 1\n  not a key \nCERT=again\n"))
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+
+	expected := Warning{4, SeverityWarning, "duplicate key CERT, first set on line 1"}
+	if len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("Expected only '%v', got %v", expected, warnings)
+	}
+}
+
+func TestLintCleanFile(t *testing.T) {
+	warnings, err := Lint(strings.NewReader("# comment\nOPTION_A=1\nexport OPTION_B='two words'\n"))
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings for a clean file, got %v", warnings)
+	}
+}
+
+func TestLintUTF16(t *testing.T) {
+	file, err := os.Open("fixtures/utf16le.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	warnings, err := Lint(file)
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected a UTF-16 file to lint like its UTF-8 content, got %v", warnings)
+	}
+}
+
+func TestLintRequiredReferences(t *testing.T) {
+	os.Clearenv()
+	file, err := os.Open("fixtures/requiredrefs.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	warnings, err := Lint(file)
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("Expected no warnings whatever the env has, got %v %v", warnings, err)
+	}
+	if err := Check("fixtures/requiredrefs.env"); err != nil {
+		t.Errorf("Expected Check to pass whatever the env has, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	os.Clearenv()
+
+	if err := Check("fixtures/plain.env", "fixtures/quoted.env", "fixtures/blockscalars.env"); err != nil {
+		t.Errorf("Expected good files to pass, got %v", err)
+	}
+
+	err := Check("fixtures/plain.env", "fixtures/lint.env", "somefilethatwillneverexistever.env")
+	if err == nil {
+		t.Fatal("Expected bad files to fail")
+	}
+
+	expected := `fixtures/lint.env:5: duplicate key OPTION_A, first set on line 2
+fixtures/lint.env:9: Can't separate key from value
+open somefilethatwillneverexistever.env: no such file or directory`
+	if err.Error() != expected {
+		t.Errorf("Expected problems\n%v\ngot\n%v", expected, err)
+	}
+
+	if os.Getenv("OPTION_A") != "" {
+		t.Error("Check changed the env")
+	}
+}
+
+func TestCheckLineEndings(t *testing.T) {
+	if err := Check("fixtures/crlfblank.env"); err != nil {
+		t.Errorf("Expected a CRLF file with a blank line to pass, got %v", err)
+	}
+	err := Check("fixtures/lonecr.env")
+	if err == nil || !strings.Contains(err.Error(), "fixtures/lonecr.env:3: empty key") {
+		t.Errorf("Expected the empty key on line 3, got %v", err)
+	}
+}
+
+func TestLintUnquotedDollar(t *testing.T) {
+	file, err := os.Open("fixtures/dollars.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	warnings, err := Lint(file)
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+
+	expected := Warning{1, SeverityWarning, `unquoted value for PASSWORD contains a bare $ reference, which is expanded; single quote it or write \$ if it's meant literally`}
+	if len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("Expected just '%v', got %v", expected, warnings)
+	}
+}
+
+func TestOnWarning(t *testing.T) {
+	os.Clearenv()
+
+	var warnings []Warning
+	opts := Options{OnWarning: func(w Warning) error {
+		warnings = append(warnings, w)
+		return nil
+	}}
+	envMap, err := ReadWithOptions(opts, "fixtures/dollars.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 1 || !strings.Contains(warnings[0].Message, "PASSWORD") {
+		t.Errorf("Expected a warning about PASSWORD, got %v", warnings)
+	}
+	if envMap["PASSWORD"] != "a" {
+		t.Errorf("Expected the value to be parsed as usual, got '%v'", envMap["PASSWORD"])
+	}
+
+	opts.OnWarning = func(w Warning) error {
+		return errors.New(w.Message)
+	}
+	_, err = ReadWithOptions(opts, "fixtures/dollars.env")
+	if err == nil || !strings.Contains(err.Error(), "line 1: unquoted value for PASSWORD") {
+		t.Errorf("Expected the hook's error to stop parsing, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package godotenv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadContext is Load, but gives up once ctx is done, so a hung NFS or FUSE
+// mount can't stall startup forever. Use context.WithTimeout for a deadline.
+// Nothing from a file that's still being read when ctx ends is loaded, though
+// files that finished before that will have been.
+//
+// Each file is read in its own goroutine, which is left behind if ctx ends
+// while the read is stuck. That only matters on pathological filesystems, on
+// healthy ones Load does the same job without the goroutines.
+func LoadContext(ctx context.Context, filenames ...string) error {
+	loaded := newOrderedEnv()
+	for _, filename := range filenamesOrDefault(filenames) {
+		content, err := readFileContext(ctx, filename)
+		if err != nil {
+			return err
+		}
+
+		env, err := parse(bytes.NewReader(content), Options{}, loaded.values)
+		if err != nil {
+			return fmt.Errorf("parsing %q: %w", filename, err)
+		}
+		env.apply()
+		loaded.merge(env)
+	}
+	return nil
+}
+
+type readResult struct {
+	content []byte
+	err     error
+}
+
+func readFileContext(ctx context.Context, filename string) ([]byte, error) {
+	// buffered so an abandoned read can still finish and be collected
+	done := make(chan readResult, 1)
+	go func() {
+		content, err := ioutil.ReadFile(filename)
+		done <- readResult{content, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.content, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("reading %v: %w", filename, ctx.Err())
+	}
+}
@@ -0,0 +1,25 @@
+package godotenv
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadContext(t *testing.T) {
+	os.Clearenv()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := LoadContext(ctx, "fixtures/plain.env", "fixtures/quoted.env"); err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	if os.Getenv("OPTION_A") != "1" || os.Getenv("OPTION_H") != "\n" {
+		t.Errorf("Expected both files to be loaded, got OPTION_A='%v' OPTION_H='%v'", os.Getenv("OPTION_A"), os.Getenv("OPTION_H"))
+	}
+
+	if err := LoadContext(ctx, "somefilethatwillneverexistever.env"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package godotenv
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoadContextTimesOut(t *testing.T) {
+	// reading a fifo nobody writes to blocks, like a hung mount would
+	dir, err := ioutil.TempDir("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fifo := filepath.Join(dir, "hung.env")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		t.Skipf("can't make a fifo: %v", err)
+	}
+
+	os.Clearenv()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = LoadContext(ctx, "fixtures/plain.env", fifo)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+	if os.Getenv("OPTION_A") != "1" {
+		t.Error("Expected the file read before the timeout to be loaded")
+	}
+
+	// let the stuck read finish so the goroutine doesn't outlive the test
+	if writer, err := os.OpenFile(fifo, os.O_WRONLY, 0); err == nil {
+		writer.Close()
+	}
+}
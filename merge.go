@@ -0,0 +1,181 @@
+package godotenv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MergeInto updates targetFile in place with the values in sourceMap, for tools
+// that reconcile computed config into a file people also edit. A key already
+// in the file has its value replaced where it stands, keeping the rest of the
+// line, comment included. Keys that aren't in the file yet are added at the
+// end, sorted. Every other line is left exactly as it was.
+//
+// The new content is written to a temporary file alongside targetFile which is
+// then renamed over it, so readers see either the old file or the new one.
+func MergeInto(sourceMap map[string]string, targetFile string) error {
+	content, err := ioutil.ReadFile(targetFile)
+	if err != nil {
+		return err
+	}
+
+	merged, found := rewriteValues(string(content), func(key string) (string, bool) {
+		value, ok := sourceMap[key]
+		return quoteForMerge(value), ok
+	})
+
+	var missing []string
+	for key := range sourceMap {
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 && len(merged) > 0 && !strings.HasSuffix(merged, "\n") {
+		merged += "\n"
+	}
+	for _, key := range missing {
+		merged += fmt.Sprintf("%s=%s\n", keyEscaper.Replace(key), quoteForMerge(sourceMap[key]))
+	}
+
+	return writeFileAtomically(targetFile, []byte(merged))
+}
+
+// rewriteValues replaces the values of the assignments in content with what
+// replace returns for their keys, already quoted as needed, leaving those it
+// says no to alone. It reports which keys were replaced. Keys are found the
+// way the parser finds them, but nothing is expanded, and a block scalar is
+// replaced as a whole, its indented lines and all.
+func rewriteValues(content string, replace func(key string) (string, bool)) (string, map[string]bool) {
+	found := make(map[string]bool)
+	content = string(decodeUTF16([]byte(content)))
+
+	var assignments []assignment
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return content, found
+	}
+	p.collectErrors = true
+	p.onAssign = func(a assignment) {
+		assignments = append(assignments, a)
+	}
+	if err := p.parse(strings.NewReader(content)); err != nil {
+		return content, found
+	}
+
+	lines := splitLinesWithEndings(content)
+	var merged strings.Builder
+	next := 0
+	for _, a := range assignments {
+		for ; next < a.first-1; next++ {
+			merged.WriteString(lines[next])
+		}
+		span := lines[a.first-1 : a.last]
+		next = a.last
+
+		value, ok := replace(a.key)
+		if !ok {
+			merged.WriteString(strings.Join(span, ""))
+			continue
+		}
+		found[a.key] = true
+
+		// a block scalar's value is its indented lines, which all go, but blank
+		// lines it ran on over belong to what follows
+		var after []string
+		if _, _, isBlock := parseBlockScalarHeader(a.text); isBlock {
+			end := len(span)
+			for end > 1 && strings.TrimSpace(span[end-1]) == "" {
+				end--
+			}
+			span, after = span[:1], span[end:]
+		}
+
+		line := strings.Join(span, "")
+		body := strings.TrimRight(line, "\r\n")
+		ending := line[len(body):]
+
+		assignment := stripComment(body, "#", false, Options{})
+		rawKey, rawValue, _ := splitKeyValue(assignment)
+		separator := assignment[len(rawKey) : len(rawKey)+1]
+		leading := rawValue[:len(rawValue)-len(strings.TrimLeft(rawValue, " \t"))]
+		trailing := assignment[len(strings.TrimRight(assignment, " \t")):]
+		comment := body[len(assignment):]
+		if value == "" && trailing+comment == "" {
+			leading = ""
+		}
+
+		merged.WriteString(rawKey + separator + leading + value + trailing + comment + ending)
+		merged.WriteString(strings.Join(after, ""))
+	}
+	merged.WriteString(strings.Join(lines[next:], ""))
+	return merged.String(), found
+}
+
+// splitLinesWithEndings is splitLines keeping each line's ending on it
+func splitLinesWithEndings(content string) []string {
+	var lines []string
+	for rest := []byte(content); len(rest) > 0; {
+		advance, _, _ := scanLines(rest, true)
+		lines = append(lines, string(rest[:advance]))
+		rest = rest[advance:]
+	}
+	return lines
+}
+
+func quoteForMerge(value string) string {
+	return `"` + doubleQuoteEscape(value) + `"`
+}
+
+// writeFileAtomically replaces filename with content via a rename, keeping
+// filename's permissions
+func writeFileAtomically(filename string, content []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(info.Mode().Perm()); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filename)
+}
+
+// GenerateExample writes exampleFile as a copy of sourceFile with every value
+// swapped for placeholder (blank if it's ""), for keeping a committed
+// .env.example in step with a real .env. Comments, blank lines and the order
+// of keys all carry over, only the values are dropped.
+func GenerateExample(sourceFile, exampleFile string, placeholder string) error {
+	content, err := ioutil.ReadFile(sourceFile)
+	if err != nil {
+		return err
+	}
+
+	replacement := ""
+	if placeholder != "" {
+		replacement = quoteForMerge(placeholder)
+	}
+	example, _ := rewriteValues(string(content), func(key string) (string, bool) {
+		return replacement, true
+	})
+
+	return ioutil.WriteFile(exampleFile, []byte(example), 0644)
+}
@@ -0,0 +1,172 @@
+package godotenv
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestMergeInto(t *testing.T) {
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	original := `# database settings
+DB_HOST=localhost # change me in prod
+export DB_PORT = "5432"
+
+# leave this one alone
+LOG_LEVEL=debug
+CERT='-----BEGIN-----
+old
+-----END-----'
+`
+	file.WriteString(original)
+	file.Close()
+	os.Chmod(file.Name(), 0600)
+
+	sourceMap := map[string]string{
+		"DB_HOST":  "db.internal",
+		"DB_PORT":  "6432",
+		"CERT":     "new",
+		"NEW_KEY":  "added",
+		"ALSO_NEW": "with \"quotes\"",
+	}
+	if err := MergeInto(sourceMap, file.Name()); err != nil {
+		t.Fatalf("Error merging: %v", err)
+	}
+
+	expected := `# database settings
+DB_HOST="db.internal" # change me in prod
+export DB_PORT = "6432"
+
+# leave this one alone
+LOG_LEVEL=debug
+CERT="new"
+ALSO_NEW="with \"quotes\""
+NEW_KEY="added"
+`
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected merged file\n%v\ngot\n%v", expected, string(content))
+	}
+
+	os.Clearenv()
+	envMap, err := Read(file.Name())
+	if err != nil {
+		t.Fatalf("Error reading merged file: %v", err)
+	}
+	for key, value := range sourceMap {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, envMap[key])
+		}
+	}
+
+	if info, err := os.Stat(file.Name()); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the permissions to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestMergeIntoBlockScalar(t *testing.T) {
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("CERT: |\n  a: 1\n  b\n\nLOG_LEVEL=debug\n")
+	file.Close()
+
+	if err := MergeInto(map[string]string{"CERT": "new", "a": "2"}, file.Name()); err != nil {
+		t.Fatalf("Error merging: %v", err)
+	}
+
+	expected := "CERT: \"new\"\n\nLOG_LEVEL=debug\na=\"2\"\n"
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected merged file\n%q\ngot\n%q", expected, string(content))
+	}
+}
+
+func TestMergeIntoUnsetReference(t *testing.T) {
+	os.Clearenv()
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("DB_URL=${HOST:?need host}\n")
+	file.Close()
+
+	if err := MergeInto(map[string]string{"DB_URL": "postgres://db"}, file.Name()); err != nil {
+		t.Fatalf("Error merging: %v", err)
+	}
+
+	expected := "DB_URL=\"postgres://db\"\n"
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected merged file\n%q\ngot\n%q", expected, string(content))
+	}
+}
+
+func TestMergeIntoMissingFile(t *testing.T) {
+	err := MergeInto(map[string]string{"A": "1"}, "somefilethatwillneverexistever.env")
+	if err == nil {
+		t.Error("Expected a missing target to be an error")
+	}
+}
+
+func TestGenerateExample(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	exampleFile := dir + "/.env.example"
+
+	if err := GenerateExample("fixtures/example.env", exampleFile, ""); err != nil {
+		t.Fatalf("Error generating example: %v", err)
+	}
+	expected := `# database settings
+DB_HOST= # where postgres lives
+export DB_PASSWORD=
+
+# the key everything signs with
+SIGNING_KEY=
+TLS_CERT:
+`
+	content, err := ioutil.ReadFile(exampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected example\n%v\ngot\n%v", expected, string(content))
+	}
+
+	if err := GenerateExample("fixtures/example.env", exampleFile, "changeme"); err != nil {
+		t.Fatalf("Error generating example: %v", err)
+	}
+	os.Clearenv()
+	envMap, err := Read(exampleFile)
+	if err != nil {
+		t.Fatalf("Error reading example: %v", err)
+	}
+	for _, key := range []string{"DB_HOST", "DB_PASSWORD", "SIGNING_KEY", "TLS_CERT"} {
+		if envMap[key] != "changeme" {
+			t.Errorf("Expected %v to be the placeholder, got '%v'", key, envMap[key])
+		}
+	}
+}
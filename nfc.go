@@ -0,0 +1,16 @@
+//go:build godotenv_nfc
+// +build godotenv_nfc
+
+package godotenv
+
+import "golang.org/x/text/unicode/norm"
+
+// NFC returns s in Unicode Normalization Form C, for Options.NormalizeKeys and
+// NormalizeValues, so decomposed text (NFD, as macOS often produces) matches
+// the composed form everything else uses. It's only built with the
+// godotenv_nfc tag, which brings in golang.org/x/text:
+//
+//	go build -tags godotenv_nfc
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
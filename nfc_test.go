@@ -0,0 +1,31 @@
+//go:build godotenv_nfc
+// +build godotenv_nfc
+
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNFC(t *testing.T) {
+	os.Clearenv()
+	envMap, err := ReadWithOptions(Options{NormalizeKeys: NFC, NormalizeValues: NFC}, "fixtures/decomposed.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	if value, ok := envMap["CAFÉ"]; !ok || value != "crème brûlée" {
+		t.Errorf("Expected the composed key and value, got %q", envMap)
+	}
+
+	if err := LoadWithOptions(Options{NormalizeKeys: NFC}, "fixtures/decomposed.env"); err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	if os.Getenv("CAFÉ") != "crème brûlée" {
+		t.Errorf("Expected os.Getenv to find the composed key, got %q", os.Getenv("CAFÉ"))
+	}
+
+	if composed := NFC("CAFÉ"); composed != "CAFÉ" {
+		t.Errorf("Expected composed text to be left alone, got %q", composed)
+	}
+}
@@ -0,0 +1,47 @@
+package godotenv
+
+import "os"
+
+// Pair is one key and its value, for when order matters
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// ReadPairs is Read, but keeps the keys in the order they first appear in
+// the files
+func ReadPairs(filenames ...string) (pairs []Pair, err error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return
+	}
+	for _, key := range env.keys {
+		if _, exists := os.LookupEnv(key); !exists {
+			pairs = append(pairs, Pair{Key: key, Value: env.values[key]})
+		}
+	}
+	return
+}
+
+// ToMap turns pairs into a map. When a key appears more than once the last
+// value wins, as it would reading a file.
+func ToMap(pairs []Pair) map[string]string {
+	envMap := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		envMap[pair.Key] = pair.Value
+	}
+	return envMap
+}
+
+// ToPairs turns envMap into pairs in keyOrder. Keys in keyOrder that aren't
+// in envMap are skipped, and so are keys in envMap that aren't in keyOrder,
+// so keyOrder picks what's included as well as the order.
+func ToPairs(envMap map[string]string, keyOrder []string) []Pair {
+	pairs := make([]Pair, 0, len(keyOrder))
+	for _, key := range keyOrder {
+		if value, ok := envMap[key]; ok {
+			pairs = append(pairs, Pair{Key: key, Value: value})
+		}
+	}
+	return pairs
+}
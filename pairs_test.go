@@ -0,0 +1,45 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestReadPairs(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_C", "already set")
+
+	pairs, err := ReadPairs("fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	expected := []Pair{{"OPTION_A", "1"}, {"OPTION_B", "2"}, {"OPTION_D", "4"}, {"OPTION_E", "5"}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Expected %v got %v", expected, pairs)
+	}
+	for i, pair := range pairs {
+		if pair != expected[i] {
+			t.Errorf("Expected %v got %v", expected[i], pair)
+		}
+	}
+}
+
+func TestToMap(t *testing.T) {
+	envMap := ToMap([]Pair{{"A", "1"}, {"B", "2"}, {"A", "3"}})
+	if len(envMap) != 2 || envMap["A"] != "3" || envMap["B"] != "2" {
+		t.Errorf("Expected the last A to win, got %v", envMap)
+	}
+}
+
+func TestToPairs(t *testing.T) {
+	envMap := map[string]string{"A": "1", "B": "2", "C": "3"}
+	pairs := ToPairs(envMap, []string{"C", "MISSING", "A"})
+	expected := []Pair{{"C", "3"}, {"A", "1"}}
+	if len(pairs) != len(expected) || pairs[0] != expected[0] || pairs[1] != expected[1] {
+		t.Errorf("Expected %v got %v", expected, pairs)
+	}
+
+	if roundTrip := ToMap(ToPairs(envMap, []string{"A", "B", "C"})); len(roundTrip) != 3 || roundTrip["B"] != "2" {
+		t.Errorf("Expected a round trip to give the map back, got %v", roundTrip)
+	}
+}
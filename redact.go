@@ -0,0 +1,38 @@
+package godotenv
+
+import "strings"
+
+// DefaultSecretKeys is what Redact looks for when it isn't given a list
+var DefaultSecretKeys = []string{"_KEY", "_SECRET", "_TOKEN", "PASSWORD"}
+
+// Redact returns a copy of envMap that's safe to log, with the values of secret
+// looking keys replaced by ***. A key is secret if it equals or ends with one
+// of secretKeys (ignoring case), so "_TOKEN" catches GITHUB_TOKEN. A nil
+// secretKeys means DefaultSecretKeys. envMap itself isn't modified.
+func Redact(envMap map[string]string, secretKeys []string) map[string]string {
+	if secretKeys == nil {
+		secretKeys = DefaultSecretKeys
+	}
+
+	return RedactFunc(envMap, func(key string) bool {
+		upperKey := strings.ToUpper(key)
+		for _, secretKey := range secretKeys {
+			if strings.HasSuffix(upperKey, strings.ToUpper(secretKey)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RedactFunc is Redact with your own rule for which keys are secret
+func RedactFunc(envMap map[string]string, isSecret func(key string) bool) map[string]string {
+	redacted := make(map[string]string, len(envMap))
+	for key, value := range envMap {
+		if isSecret(key) {
+			value = "***"
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
@@ -0,0 +1,52 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	envMap := map[string]string{
+		"AWS_ACCESS_KEY":  "AKIA",
+		"APP_SECRET":      "shh",
+		"github_token":    "ghp",
+		"DB_PASSWORD":     "hunter2",
+		"PASSWORD":        "hunter3",
+		"HOST":            "localhost",
+		"KEYBOARD_LAYOUT": "dvorak",
+	}
+
+	redacted := Redact(envMap, nil)
+	expected := map[string]string{
+		"AWS_ACCESS_KEY":  "***",
+		"APP_SECRET":      "***",
+		"github_token":    "***",
+		"DB_PASSWORD":     "***",
+		"PASSWORD":        "***",
+		"HOST":            "localhost",
+		"KEYBOARD_LAYOUT": "dvorak",
+	}
+	for key, value := range expected {
+		if redacted[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, redacted[key])
+		}
+	}
+
+	if envMap["DB_PASSWORD"] != "hunter2" {
+		t.Error("Redact modified the map it was given")
+	}
+
+	redacted = Redact(envMap, []string{"HOST"})
+	if redacted["HOST"] != "***" || redacted["DB_PASSWORD"] != "hunter2" {
+		t.Errorf("Expected only the listed keys to be redacted, got %v", redacted)
+	}
+}
+
+func TestRedactFunc(t *testing.T) {
+	envMap := map[string]string{"VAULT_ADDR": "https://vault", "VAULT_ROLE": "app", "HOST": "localhost"}
+
+	redacted := RedactFunc(envMap, func(key string) bool { return strings.HasPrefix(key, "VAULT_") })
+	if redacted["VAULT_ADDR"] != "***" || redacted["VAULT_ROLE"] != "***" || redacted["HOST"] != "localhost" {
+		t.Errorf("Expected the VAULT_ keys to be redacted, got %v", redacted)
+	}
+}
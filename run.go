@@ -0,0 +1,31 @@
+package godotenv
+
+import "os"
+
+// Run reads the files (or .env) and calls fn with what they contain, for CLIs
+// that want their config as data rather than global state. The environment is
+// neither consulted nor changed, use RunAndLoad for that. It returns the error
+// from reading the files, in which case fn isn't called, or else fn's error.
+func Run(filenames []string, fn func(cfg map[string]string) error) error {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return err
+	}
+	return fn(env.values)
+}
+
+// RunAndLoad is Run, but the files are loaded into the environment first, the
+// same as Load, and fn gets the values that ended up in effect. Where a variable
+// was already set that's its existing value rather than the file's.
+func RunAndLoad(filenames []string, fn func(cfg map[string]string) error) error {
+	env, err := loadFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return err
+	}
+
+	cfg := make(map[string]string, len(env.keys))
+	for _, key := range env.keys {
+		cfg[key] = os.Getenv(key)
+	}
+	return fn(cfg)
+}
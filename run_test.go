@@ -0,0 +1,71 @@
+package godotenv
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "from env")
+
+	var got map[string]string
+	err := Run([]string{"fixtures/plain.env"}, func(cfg map[string]string) error {
+		got = cfg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got["OPTION_A"] != "1" || got["OPTION_E"] != "5" {
+		t.Errorf("Expected the file's values, got %v", got)
+	}
+	if os.Getenv("OPTION_B") != "" {
+		t.Error("Expected Run to leave the env alone")
+	}
+}
+
+func TestRunAndLoad(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "from env")
+
+	var got map[string]string
+	err := RunAndLoad([]string{"fixtures/plain.env"}, func(cfg map[string]string) error {
+		got = cfg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got["OPTION_A"] != "from env" || got["OPTION_B"] != "2" {
+		t.Errorf("Expected the values in effect, got %v", got)
+	}
+	if os.Getenv("OPTION_B") != "2" {
+		t.Error("Expected RunAndLoad to load the file")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	called := false
+	err := Run([]string{"somefilethatwillneverexistever.env"}, func(cfg map[string]string) error {
+		called = true
+		return nil
+	})
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected the load error, got %v", err)
+	}
+	if called {
+		t.Error("Expected fn not to be called when loading fails")
+	}
+
+	fnErr := errors.New("fn failed")
+	for _, run := range []func([]string, func(map[string]string) error) error{Run, RunAndLoad} {
+		err = run([]string{"fixtures/plain.env"}, func(cfg map[string]string) error {
+			return fnErr
+		})
+		if err != fnErr {
+			t.Errorf("Expected fn's error, got %v", err)
+		}
+	}
+}
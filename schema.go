@@ -0,0 +1,119 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type a SchemaField's value is coerced to
+type FieldType int
+
+const (
+	// FieldString values are used as they are
+	FieldString FieldType = iota
+	// FieldInt values are parsed with strconv.Atoi into an int
+	FieldInt
+	// FieldFloat values are parsed into a float64
+	FieldFloat
+	// FieldBool values take the same spellings as GetBoolExtended
+	FieldBool
+	// FieldDuration values are parsed with time.ParseDuration
+	FieldDuration
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldString:
+		return "string"
+	case FieldInt:
+		return "int"
+	case FieldFloat:
+		return "float"
+	case FieldBool:
+		return "bool"
+	case FieldDuration:
+		return "duration"
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+// SchemaField describes one key BuildConfig should produce
+type SchemaField struct {
+	Name string
+	Type FieldType
+	// Default is used when the key isn't set anywhere, and is coerced just
+	// like a value from a file would be. It's ignored for Required fields, and
+	// an optional field with no Default is left out when it isn't set.
+	Default string
+	// Required makes it an error for the key not to be set anywhere
+	Required bool
+}
+
+// BuildConfig reads the files (or .env) and returns a value for every field in
+// schema, coerced to the field's type. As with Load, a variable already in the
+// environment wins over the files, though the environment isn't changed.
+// Unset fields get their Default, or are an error if Required. Unset optional
+// fields without a Default are left out of the map, so check with the two
+// value form of a map lookup. Every problem is reported in the one error
+// rather than stopping at the first.
+func BuildConfig(schema []SchemaField, filenames ...string) (map[string]interface{}, error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]interface{}, len(schema))
+	var problems []string
+	for _, field := range schema {
+		raw, ok := os.LookupEnv(field.Name)
+		if !ok {
+			raw, ok = env.values[field.Name]
+		}
+		if !ok {
+			switch {
+			case field.Required:
+				problems = append(problems, fmt.Sprintf("%v is required", field.Name))
+				continue
+			case field.Default == "":
+				// optional and nothing to fall back on, so it's left out
+				continue
+			}
+			raw = field.Default
+		}
+
+		value, err := coerceField(raw, field.Type)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%v: %q isn't a valid %v", field.Name, raw, field.Type))
+			continue
+		}
+		config[field.Name] = value
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "\n"))
+	}
+	return config, nil
+}
+
+func coerceField(raw string, fieldType FieldType) (interface{}, error) {
+	switch fieldType {
+	case FieldString:
+		return raw, nil
+	case FieldInt:
+		return strconv.Atoi(strings.TrimSpace(raw))
+	case FieldFloat:
+		return strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	case FieldBool:
+		if value, ok := parseBoolExtended(raw); ok {
+			return value, nil
+		}
+		return nil, errors.New("invalid bool")
+	case FieldDuration:
+		return time.ParseDuration(strings.TrimSpace(raw))
+	}
+	return nil, fmt.Errorf("unknown field type %v", fieldType)
+}
@@ -0,0 +1,90 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildConfig(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+
+	schema := []SchemaField{
+		{Name: "HOST", Type: FieldString, Required: true},
+		{Name: "PORT", Type: FieldInt, Required: true},
+		{Name: "DEBUG", Type: FieldBool},
+		{Name: "TIMEOUT", Type: FieldDuration},
+		{Name: "RATIO", Type: FieldFloat},
+		{Name: "RETRIES", Type: FieldInt, Default: "3"},
+	}
+	config, err := BuildConfig(schema, "fixtures/schema.env")
+	if err != nil {
+		t.Fatalf("Error building config: %v", err)
+	}
+
+	expectedValues := map[string]interface{}{
+		"HOST":    "example.com",
+		"PORT":    8080,
+		"DEBUG":   true,
+		"TIMEOUT": 90 * time.Second,
+		"RATIO":   0.75,
+		"RETRIES": 3,
+	}
+	for key, value := range expectedValues {
+		if config[key] != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' (%T) got '%v' (%T)", key, value, value, config[key], config[key])
+		}
+	}
+
+	if os.Getenv("PORT") != "" {
+		t.Error("Expected BuildConfig to leave the env alone")
+	}
+}
+
+func TestBuildConfigUnsetOptionalFields(t *testing.T) {
+	os.Clearenv()
+
+	schema := []SchemaField{
+		{Name: "UNSET_INT", Type: FieldInt},
+		{Name: "UNSET_BOOL", Type: FieldBool},
+		{Name: "UNSET_DURATION", Type: FieldDuration},
+		{Name: "UNSET_STRING", Type: FieldString},
+	}
+	config, err := BuildConfig(schema, "fixtures/schema.env")
+	if err != nil {
+		t.Fatalf("Expected unset optional fields to be fine, got %v", err)
+	}
+	for _, field := range schema {
+		if value, ok := config[field.Name]; ok {
+			t.Errorf("Expected %v to be left out, got '%v'", field.Name, value)
+		}
+	}
+}
+
+func TestBuildConfigReportsEveryProblem(t *testing.T) {
+	os.Clearenv()
+
+	schema := []SchemaField{
+		{Name: "MISSING", Type: FieldString, Required: true},
+		{Name: "BAD_PORT", Type: FieldInt},
+		{Name: "HOST", Type: FieldBool},
+		{Name: "BAD_DEFAULT", Type: FieldDuration, Default: "soon"},
+	}
+	config, err := BuildConfig(schema, "fixtures/schema.env")
+	if err == nil {
+		t.Fatalf("Expected an error, got %v", config)
+	}
+
+	for _, expected := range []string{
+		"MISSING is required",
+		`BAD_PORT: "eighty" isn't a valid int`,
+		`HOST: "localhost" isn't a valid bool`,
+		`BAD_DEFAULT: "soon" isn't a valid duration`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to contain %q, got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package godotenv
+
+import (
+	"io"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// SecretPattern is something ScanForSecrets looks for. Key and Value are
+// matched against an assignment's key and value, both have to match and a nil
+// one matches anything.
+type SecretPattern struct {
+	Category string
+	Key      *regexp.Regexp
+	Value    *regexp.Regexp
+}
+
+// DefaultSecretPatterns is what ScanForSecrets looks for. Append your own to
+// a copy of it and use ScanForSecretsWithPatterns for anything team specific.
+var DefaultSecretPatterns = []SecretPattern{
+	{Category: "aws-access-key", Value: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Category: "aws-secret-key", Key: regexp.MustCompile(`(?i)aws.*secret`), Value: regexp.MustCompile(`^[A-Za-z0-9/+=]{40}$`)},
+	{Category: "private-key", Value: regexp.MustCompile(`-----BEGIN ([A-Z]+ )*PRIVATE KEY-----`)},
+	{Category: "password", Key: regexp.MustCompile(`(?i)(passw(or)?d|pwd)$`), Value: regexp.MustCompile(`.`)},
+}
+
+// high entropy values are at least this long, random looking and made of the
+// characters tokens are, which leaves out urls and paths
+const (
+	minHighEntropyLength = 20
+	minHighEntropyBits   = 4.0
+)
+
+// referenceMark is what a reference expands to while scanning, to be dropped
+const referenceMark = "\x00"
+
+var tokenChars = regexp.MustCompile(`^[A-Za-z0-9+/=_-]+$`)
+
+// Finding is a value ScanForSecrets thinks is a secret
+type Finding struct {
+	// Line is the 1-based line the assignment starts on
+	Line     int
+	Key      string
+	Category string
+}
+
+// ScanForSecrets reads an env file from r and reports the values that look
+// like real secrets, for a pre-commit hook to keep them out of a tracked file.
+// It checks DefaultSecretPatterns, and flags long random looking values as
+// "high-entropy". The file is parsed with the standard rules, except that
+// references are dropped rather than expanded, so abc${X}def is scanned as
+// abcdef: what they expand to isn't in the file. Nothing is applied to the
+// environment. Lines that don't parse are skipped, use Lint to find those.
+func ScanForSecrets(r io.Reader) ([]Finding, error) {
+	return ScanForSecretsWithPatterns(r, DefaultSecretPatterns)
+}
+
+// ScanForSecretsWithPatterns is ScanForSecrets with your own patterns
+func ScanForSecretsWithPatterns(r io.Reader, patterns []SecretPattern) (findings []Finding, err error) {
+	opts := Options{
+		// what's committed is what matters, not what it expands to. References
+		// stand in as referenceMark so ${VAR:?} is still satisfied.
+		lookup: func(name string) (string, bool) { return referenceMark, true },
+	}
+	p, err := newParser(opts, nil)
+	if err != nil {
+		return
+	}
+	p.collectErrors = true
+	p.onAssign = func(a assignment) {
+		value := strings.Replace(a.value, referenceMark, "", -1)
+		if category, ok := secretCategory(a.key, value, patterns); ok {
+			findings = append(findings, Finding{Line: a.first, Key: a.key, Category: category})
+		}
+	}
+	err = p.parse(r)
+	return
+}
+
+func secretCategory(key, value string, patterns []SecretPattern) (string, bool) {
+	for _, pattern := range patterns {
+		if (pattern.Key == nil || pattern.Key.MatchString(key)) && (pattern.Value == nil || pattern.Value.MatchString(value)) {
+			return pattern.Category, true
+		}
+	}
+	if isHighEntropy(value) {
+		return "high-entropy", true
+	}
+	return "", false
+}
+
+// isHighEntropy reports whether value is a long token with enough Shannon
+// entropy per character to look randomly generated
+func isHighEntropy(value string) bool {
+	if len(value) < minHighEntropyLength || !tokenChars.MatchString(value) {
+		return false
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, char := range value {
+		counts[char]++
+		total++
+	}
+	var bits float64
+	for _, count := range counts {
+		frequency := float64(count) / float64(total)
+		bits -= frequency * math.Log2(frequency)
+	}
+	return bits >= minHighEntropyBits
+}
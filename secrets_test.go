@@ -0,0 +1,68 @@
+package godotenv
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestScanForSecrets(t *testing.T) {
+	os.Clearenv()
+	file, err := os.Open("fixtures/leakedsecrets.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	findings, err := ScanForSecrets(file)
+	if err != nil {
+		t.Fatalf("Error scanning: %v", err)
+	}
+
+	expected := []Finding{
+		{Line: 3, Key: "AWS_ACCESS_KEY_ID", Category: "aws-access-key"},
+		{Line: 4, Key: "AWS_SECRET_ACCESS_KEY", Category: "aws-secret-key"},
+		{Line: 5, Key: "DB_PASSWORD", Category: "password"},
+		{Line: 7, Key: "TLS_KEY", Category: "private-key"},
+		{Line: 10, Key: "SESSION_SECRET", Category: "high-entropy"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings got %d: %+v", len(expected), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding != expected[i] {
+			t.Errorf("Expected %+v got %+v", expected[i], finding)
+		}
+	}
+
+	if len(os.Environ()) != 0 {
+		t.Error("Expected ScanForSecrets to leave the env alone")
+	}
+}
+
+func TestScanForSecretsWithPatterns(t *testing.T) {
+	patterns := append([]SecretPattern{}, DefaultSecretPatterns...)
+	patterns = append(patterns, SecretPattern{Category: "acme-token", Value: regexp.MustCompile(`^acme_live_`)})
+
+	findings, err := ScanForSecretsWithPatterns(strings.NewReader("LOG_LEVEL=debug\nACME_TOKEN=acme_live_1234\n"), patterns)
+	if err != nil {
+		t.Fatalf("Error scanning: %v", err)
+	}
+	expected := Finding{Line: 2, Key: "ACME_TOKEN", Category: "acme-token"}
+	if len(findings) != 1 || findings[0] != expected {
+		t.Errorf("Expected only %+v got %+v", expected, findings)
+	}
+}
+
+func TestScanForSecretsDropsReferences(t *testing.T) {
+	os.Clearenv()
+	findings, err := ScanForSecretsWithPatterns(strings.NewReader("DB_PASSWORD=${DB_PASSWORD:?required}\nSESSION_SECRET=${PREFIX:?required}f3Kq9ZpX2mLw8RtVb7NcYh4J\n"), DefaultSecretPatterns)
+	if err != nil {
+		t.Fatalf("Error scanning: %v", err)
+	}
+	expected := Finding{Line: 2, Key: "SESSION_SECRET", Category: "high-entropy"}
+	if len(findings) != 1 || findings[0] != expected {
+		t.Errorf("Expected only %+v got %+v", expected, findings)
+	}
+}
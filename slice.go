@@ -0,0 +1,50 @@
+package godotenv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GatherSlice collects the values of keys like LIST_0, LIST_1 (for the prefix
+// LIST) into a slice ordered by index, as tools like Terraform write lists.
+// Missing indices are skipped, use GatherSliceStrict to have them be an error.
+func GatherSlice(prefix string, envMap map[string]string) []string {
+	values, _ := gatherSlice(prefix, envMap, false)
+	return values
+}
+
+// GatherSliceStrict is GatherSlice but the indices have to run from 0 with
+// none missing
+func GatherSliceStrict(prefix string, envMap map[string]string) ([]string, error) {
+	return gatherSlice(prefix, envMap, true)
+}
+
+func gatherSlice(prefix string, envMap map[string]string, strict bool) ([]string, error) {
+	byIndex := make(map[int]string)
+	indices := make([]int, 0)
+	for key, value := range envMap {
+		if !strings.HasPrefix(key, prefix+"_") {
+			continue
+		}
+		suffix := key[len(prefix)+1:]
+		index, err := strconv.Atoi(suffix)
+		// LIST_01 or LIST_+1 would clash with LIST_1, so only take the plain form
+		if err != nil || index < 0 || strconv.Itoa(index) != suffix {
+			continue
+		}
+		byIndex[index] = value
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	values := make([]string, 0, len(indices))
+	for i, index := range indices {
+		if strict && index != i {
+			return nil, fmt.Errorf("%v_%d is missing", prefix, i)
+		}
+		values = append(values, byIndex[index])
+	}
+	return values, nil
+}
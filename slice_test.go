@@ -0,0 +1,50 @@
+package godotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGatherSlice(t *testing.T) {
+	envMap := map[string]string{
+		"LIST_2":   "c",
+		"LIST_0":   "a",
+		"LIST_10":  "k",
+		"LIST_1":   "b",
+		"LIST_01":  "not an index",
+		"LIST_X":   "not an index either",
+		"LISTS_3":  "another prefix",
+		"OTHER_0":  "another prefix",
+		"LIST_0_A": "nested",
+	}
+
+	values := GatherSlice("LIST", envMap)
+	expected := []string{"a", "b", "c", "k"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v got %v", expected, values)
+	}
+
+	if values := GatherSlice("NOTHING", envMap); len(values) != 0 {
+		t.Errorf("Expected no values for an unused prefix, got %v", values)
+	}
+}
+
+func TestGatherSliceStrict(t *testing.T) {
+	values, err := GatherSliceStrict("LIST", map[string]string{"LIST_1": "b", "LIST_0": "a"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(values, []string{"a", "b"}) {
+		t.Errorf("Expected [a b] got %v", values)
+	}
+
+	_, err = GatherSliceStrict("LIST", map[string]string{"LIST_0": "a", "LIST_2": "c"})
+	if err == nil || err.Error() != "LIST_1 is missing" {
+		t.Errorf("Expected a gap to be an error, got %v", err)
+	}
+
+	_, err = GatherSliceStrict("LIST", map[string]string{"LIST_1": "b"})
+	if err == nil || err.Error() != "LIST_0 is missing" {
+		t.Errorf("Expected a missing first index to be an error, got %v", err)
+	}
+}
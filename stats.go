@@ -0,0 +1,66 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+)
+
+// FileStats describes the shape of an env file, as counted by Stats
+type FileStats struct {
+	// Lines is every physical line in the file
+	Lines int
+	// CommentLines are lines that are only a comment
+	CommentLines int
+	// BlankLines are empty or only whitespace
+	BlankLines int
+	// Assignments counts each key being set, duplicates included. One that
+	// runs over several lines counts once.
+	Assignments int
+	// QuotedValues are the assignments whose value is in quotes
+	QuotedValues int
+	// DuplicateKeys are the assignments to a key already set earlier in the file
+	DuplicateKeys int
+}
+
+// Stats counts the lines of filename by what they hold, for health checks and
+// for CI asserting a generated file has the expected shape. It parses the file
+// with the standard rules, without resolving references, and never touches the
+// environment. Lines that don't parse are counted in Lines but nothing else.
+func Stats(filename string) (stats FileStats, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	p, err := newParser(syntaxOnly(Options{}), nil)
+	if err != nil {
+		return
+	}
+	p.collectErrors = true
+	p.onSkip = func(lineNumber int, line string) {
+		if strings.TrimSpace(line) == "" {
+			stats.BlankLines++
+		} else {
+			stats.CommentLines++
+		}
+	}
+	seen := make(map[string]bool)
+	p.onAssign = func(a assignment) {
+		_, rawValue, _ := splitKeyValue(stripComment(a.text, "#", false, Options{}))
+		if rawValue = strings.TrimLeft(rawValue, " \t"); rawValue != "" && (rawValue[0] == '"' || rawValue[0] == '\'') {
+			stats.QuotedValues++
+		}
+
+		stats.Assignments++
+		if seen[a.key] {
+			stats.DuplicateKeys++
+		}
+		seen[a.key] = true
+	}
+	if err = p.parse(file); err != nil {
+		return
+	}
+	stats.Lines = p.lines.number
+	return
+}
@@ -0,0 +1,58 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	os.Clearenv()
+	stats, err := Stats("fixtures/stats.env")
+	if err != nil {
+		t.Fatalf("Error counting: %v", err)
+	}
+
+	expected := FileStats{
+		Lines:         13,
+		CommentLines:  2,
+		BlankLines:    2,
+		Assignments:   6,
+		QuotedValues:  3,
+		DuplicateKeys: 1,
+	}
+	if stats != expected {
+		t.Errorf("Expected %+v got %+v", expected, stats)
+	}
+
+	if len(os.Environ()) != 0 {
+		t.Error("Expected Stats to leave the env alone")
+	}
+
+	if _, err := Stats("somefilethatwillneverexistever.env"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
+
+func TestStatsUTF16(t *testing.T) {
+	stats, err := Stats("fixtures/utf16le.env")
+	if err != nil {
+		t.Fatalf("Error counting: %v", err)
+	}
+
+	expected := FileStats{Lines: 2, Assignments: 2, QuotedValues: 1}
+	if stats != expected {
+		t.Errorf("Expected %+v got %+v", expected, stats)
+	}
+}
+
+func TestStatsUnsetReferences(t *testing.T) {
+	os.Clearenv()
+	stats, err := Stats("fixtures/requiredrefs.env")
+	if err != nil {
+		t.Fatalf("Error counting: %v", err)
+	}
+
+	if stats.Assignments != 2 || stats.QuotedValues != 1 {
+		t.Errorf("Expected both assignments counted whatever the env holds, got %+v", stats)
+	}
+}
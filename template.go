@@ -0,0 +1,62 @@
+package godotenv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// LoadTemplate loads the files (or .env) like Load, but first runs every value
+// through text/template, so GREETING={{.USER}}-env works. It's a separate
+// function rather than an option because {{ is perfectly good literal text in
+// a normal .env file.
+//
+// Templates see the environment, overlaid by the values in the files (each
+// one already rendered, so later values can build on earlier ones), overlaid
+// by data, so data always wins. Referring to something in none of them is an
+// error, as is any other template failure, and nothing is loaded.
+func LoadTemplate(data map[string]interface{}, filenames ...string) error {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return err
+	}
+
+	context := make(map[string]interface{})
+	for _, kv := range os.Environ() {
+		key, value := SplitEntry(kv)
+		context[key] = value
+	}
+
+	for key, value := range data {
+		context[key] = value
+	}
+
+	rendered := newOrderedEnv()
+	for _, key := range env.keys {
+		value, err := renderTemplate(key, env.values[key], context)
+		if err != nil {
+			return err
+		}
+		rendered.set(key, value)
+		if _, inData := data[key]; !inData {
+			context[key] = value
+		}
+	}
+
+	rendered.apply()
+	return nil
+}
+
+func renderTemplate(key, value string, context map[string]interface{}) (string, error) {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", key, err)
+	}
+
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, context); err != nil {
+		return "", fmt.Errorf("%v: %v", key, err)
+	}
+	return output.String(), nil
+}
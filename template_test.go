@@ -0,0 +1,49 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("USER", "gopher")
+	os.Setenv("HOST", "from-env")
+
+	data := map[string]interface{}{"HOST": "from-data", "PORT": 8080}
+	if err := LoadTemplate(data, "fixtures/template.env"); err != nil {
+		t.Fatalf("Error loading template: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"GREETING": "gopher-env",
+		"URL":      "http://from-data:8080/",
+		"SHOUT":    "gopher-env!",
+		// already set, so like Load it isn't overridden
+		"HOST": "from-env",
+	}
+	for key, value := range expectedValues {
+		if os.Getenv(key) != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, os.Getenv(key))
+		}
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+
+	err := LoadTemplate(nil, "fixtures/template.env")
+	if err == nil || !strings.HasPrefix(err.Error(), "GREETING: ") {
+		t.Errorf("Expected a missing key to fail naming GREETING, got %v", err)
+	}
+	if os.Getenv("URL") != "" {
+		t.Error("Expected nothing to be loaded after an error")
+	}
+
+	_, err = renderTemplate("BROKEN", "{{.HOST", nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "BROKEN: ") {
+		t.Errorf("Expected a bad template to fail naming BROKEN, got %v", err)
+	}
+}
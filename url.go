@@ -0,0 +1,51 @@
+package godotenv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// how long LoadURL waits for the whole request before giving up
+const defaultURLTimeout = 30 * time.Second
+
+// LoadURL fetches an env file over HTTP(S) and loads it the same way Load does,
+// so it WILL NOT OVERRIDE variables that are already set. It gives up after 30
+// seconds, use LoadURLContext to control that.
+//
+// Bear in mind that whoever can answer the request controls your config, so
+// only use https urls you trust, and that values (secrets included) travel over
+// the network and may be logged along the way.
+func LoadURL(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultURLTimeout)
+	defer cancel()
+
+	return LoadURLContext(ctx, url)
+}
+
+// LoadURLContext is LoadURL with the request bound to ctx
+func LoadURLContext(ctx context.Context, url string) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %v: unexpected status %v", url, response.Status)
+	}
+
+	env, err := parse(response.Body, Options{}, nil)
+	if err != nil {
+		return err
+	}
+
+	env.apply()
+	return nil
+}
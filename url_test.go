@@ -0,0 +1,58 @@
+package godotenv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OPTION_A=1\nOPTION_B='two'\n"))
+	}))
+	defer server.Close()
+
+	os.Clearenv()
+	os.Setenv("OPTION_B", "actualenv")
+
+	if err := LoadURL(server.URL); err != nil {
+		t.Fatalf("Error loading url: %v", err)
+	}
+	if os.Getenv("OPTION_A") != "1" {
+		t.Errorf("Expected OPTION_A to be loaded, got '%v'", os.Getenv("OPTION_A"))
+	}
+	if os.Getenv("OPTION_B") != "actualenv" {
+		t.Error("An ENV var set earlier was overwritten")
+	}
+}
+
+func TestLoadURLBadStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	err := LoadURL(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+func TestLoadURLContextTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := LoadURLContext(ctx, server.URL); err == nil {
+		t.Error("Expected the request to time out")
+	}
+}
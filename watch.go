@@ -0,0 +1,123 @@
+package godotenv
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+)
+
+// how often Watch checks the files for modification
+var watchInterval = time.Second
+
+// Watch polls the given files (or .env) for modification and, when any of them
+// change, re-reads them and calls onChange with just the keys whose values differ
+// from the last time they were read, and separately the keys that have gone
+// from the files, sorted.
+//
+// Watch doesn't touch the environment itself, that's up to onChange. It blocks
+// until ctx is cancelled and then returns nil, or returns early if the files
+// can't be read to begin with.
+func Watch(ctx context.Context, onChange func(changed map[string]string, removed []string), filenames ...string) error {
+	filenames = filenamesOrDefault(filenames)
+
+	env, err := readFiles(filenames, Options{})
+	if err != nil {
+		return err
+	}
+	lastSeen := env.values
+	lastModified := modTimes(filenames)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		modified := modTimes(filenames)
+		if sameModTimes(lastModified, modified) {
+			continue
+		}
+
+		// a half written file may fail to read, just try again next tick
+		env, err := readFiles(filenames, Options{})
+		if err != nil {
+			continue
+		}
+		envMap := env.values
+		lastModified = modified
+
+		changed, removed := diffEnvMaps(lastSeen, envMap)
+		lastSeen = envMap
+		if len(changed) > 0 || len(removed) > 0 {
+			onChange(changed, removed)
+		}
+	}
+}
+
+// ReadChangedSince reads the files (or .env) and returns just the keys whose
+// values differ from previous, brand new keys included, which is what a reload
+// wants to hand on. As with Watch, keys in previous that have gone from the
+// files are returned in removed, sorted, so they can't be mistaken for keys
+// set to "". Like Watch it goes by what the files say, whether or not the
+// environment has them set.
+func ReadChangedSince(previous map[string]string, filenames ...string) (changed map[string]string, removed []string, err error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return
+	}
+	changed, removed = diffEnvMaps(previous, env.values)
+	return
+}
+
+// WouldChange reports whether loading the files (or .env) would set anything,
+// that is whether any key in them is missing from the environment. Keys that
+// are present with a different value don't count, since Load wouldn't
+// override them. A deploy step can use it to skip a restart when the config
+// has nothing new.
+func WouldChange(filenames ...string) (bool, error) {
+	env, err := readFiles(filenamesOrDefault(filenames), Options{})
+	if err != nil {
+		return false, err
+	}
+	return len(env.unset()) > 0, nil
+}
+
+func modTimes(filenames []string) []time.Time {
+	times := make([]time.Time, len(filenames))
+	for i, filename := range filenames {
+		if info, err := os.Stat(filename); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
+
+func sameModTimes(a, b []time.Time) bool {
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func diffEnvMaps(before, after map[string]string) (changed map[string]string, removed []string) {
+	changed = make(map[string]string)
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || previous != value {
+			changed[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return
+}
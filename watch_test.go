@@ -0,0 +1,139 @@
+package godotenv
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchReportsChangedKeys(t *testing.T) {
+	watchInterval = 10 * time.Millisecond
+	defer func() { watchInterval = time.Second }()
+
+	file, err := ioutil.TempFile("", "godotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("OPTION_A=1\nOPTION_B=2\nOPTION_C=3\n")
+	file.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	type change struct {
+		changed map[string]string
+		removed []string
+	}
+	changes := make(chan change, 1)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, func(changed map[string]string, removed []string) { changes <- change{changed, removed} }, file.Name())
+	}()
+
+	// make sure the rewrite lands on a different mtime
+	time.Sleep(50 * time.Millisecond)
+	ioutil.WriteFile(file.Name(), []byte("OPTION_A=1\nOPTION_B=two\nOPTION_D=4\nOPTION_E=\n"), 0644)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(file.Name(), later, later)
+
+	select {
+	case change := <-changes:
+		expected := map[string]string{"OPTION_B": "two", "OPTION_D": "4", "OPTION_E": ""}
+		if len(change.changed) != len(expected) {
+			t.Errorf("Expected changes %v, got %v", expected, change.changed)
+		}
+		for key, value := range expected {
+			if v, ok := change.changed[key]; !ok || v != value {
+				t.Errorf("Expected change %v=%q, got %v", key, value, change.changed)
+			}
+		}
+		if !reflect.DeepEqual(change.removed, []string{"OPTION_C"}) {
+			t.Errorf("Expected OPTION_C removed, got %v", change.removed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Watch never reported the change")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Watch to stop cleanly, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Watch didn't stop when the context was cancelled")
+	}
+}
+
+func TestWatchFileNotFound(t *testing.T) {
+	err := Watch(context.Background(), func(map[string]string, []string) {}, "somefilethatwillneverexistever.env")
+	if err == nil {
+		t.Error("File wasn't found but Watch didn't return an error")
+	}
+}
+
+func TestReadChangedSince(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OPTION_A", "set in the env")
+
+	previous := map[string]string{
+		"OPTION_A": "1",
+		"OPTION_B": "old",
+		"OPTION_C": "3",
+		"OPTION_Z": "gone",
+	}
+	changed, removed, err := ReadChangedSince(previous, "fixtures/plain.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expected := map[string]string{
+		"OPTION_B": "2",
+		"OPTION_D": "4",
+		"OPTION_E": "5",
+	}
+	if len(changed) != len(expected) {
+		t.Errorf("Expected %v got %v", expected, changed)
+	}
+	for key, value := range expected {
+		if actual, ok := changed[key]; !ok || actual != value {
+			t.Errorf("Mismatch for key '%v': expected '%v' got '%v'", key, value, actual)
+		}
+	}
+	if !reflect.DeepEqual(removed, []string{"OPTION_Z"}) {
+		t.Errorf("Expected OPTION_Z removed, got %v", removed)
+	}
+
+	if _, _, err := ReadChangedSince(previous, "somefilethatwillneverexistever.env"); err == nil {
+		t.Error("Expected a missing file to be an error")
+	}
+}
+
+func TestWouldChange(t *testing.T) {
+	os.Clearenv()
+	changes, err := WouldChange("fixtures/plain.env")
+	if err != nil || !changes {
+		t.Errorf("Expected changes into an empty env, got %v %v", changes, err)
+	}
+
+	if err := Load("fixtures/plain.env"); err != nil {
+		t.Fatalf("Error loading: %v", err)
+	}
+	os.Setenv("OPTION_A", "different")
+	changes, err = WouldChange("fixtures/plain.env")
+	if err != nil || changes {
+		t.Errorf("Expected no changes once every key is set, got %v %v", changes, err)
+	}
+
+	os.Unsetenv("OPTION_E")
+	changes, err = WouldChange("fixtures/plain.env")
+	if err != nil || !changes {
+		t.Errorf("Expected a change with OPTION_E unset, got %v %v", changes, err)
+	}
+
+	if _, err := WouldChange("somefilethatwillneverexistever.env"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,103 @@
+package godotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseBlockScalarHeader recognises the YAML style KEY: | and KEY: > lines
+// that start a block scalar, and their |- and >- variants that drop the final
+// newline. Only that much of YAML is understood.
+func parseBlockScalarHeader(line string) (key, indicator string, isBlock bool) {
+	rawKey, rawValue, ok := splitKeyValue(line)
+	if !ok || line[len(rawKey)] != ':' {
+		return
+	}
+
+	indicator = strings.TrimSpace(stripComment(rawValue, "#", true, Options{}))
+	switch indicator {
+	case "|", "|-", ">", ">-":
+	default:
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(rawKey)
+	if key == "" {
+		return "", "", false
+	}
+	return keyUnescaper.Replace(key), indicator, true
+}
+
+// readBlockScalar gathers the lines of a block scalar, those indented further
+// than the key at parentIndent, into its value. The first of them sets how far
+// the block is indented and none of the rest can be indented less. A | block
+// keeps its newlines, a > block folds them into spaces, leaving blank lines as
+// newlines. Either way the value ends with a single newline, unless the
+// indicator has a - after it.
+func readBlockScalar(lines *lineReader, parentIndent int, indicator string) (string, error) {
+	var block []string
+	blockIndent := -1
+	for {
+		line, ok := lines.next()
+		if !ok {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			block = append(block, "")
+			continue
+		}
+
+		indent := indentation(line)
+		if indent <= parentIndent {
+			lines.unreadLine(line)
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = indent
+		}
+		if indent < blockIndent {
+			return "", fmt.Errorf("inconsistent indentation in block on line %d", lines.number)
+		}
+		block = append(block, line[blockIndent:])
+	}
+
+	for len(block) > 0 && block[len(block)-1] == "" {
+		block = block[:len(block)-1]
+	}
+	if len(block) == 0 {
+		return "", nil
+	}
+
+	var value string
+	if strings.HasPrefix(indicator, "|") {
+		value = strings.Join(block, "\n")
+	} else {
+		value = foldLines(block)
+	}
+	if !strings.HasSuffix(indicator, "-") {
+		value += "\n"
+	}
+	return value, nil
+}
+
+// foldLines joins lines with spaces, except where a blank line stands for a
+// newline
+func foldLines(lines []string) string {
+	var folded strings.Builder
+	for i, line := range lines {
+		switch {
+		case line == "":
+			folded.WriteString("\n")
+		case i > 0 && lines[i-1] != "":
+			folded.WriteString(" " + line)
+		default:
+			folded.WriteString(line)
+		}
+	}
+	return folded.String()
+}
+
+// indentation counts the spaces a line starts with
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -0,0 +1,48 @@
+package godotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestYAMLBlockScalars(t *testing.T) {
+	os.Clearenv()
+	envMap, err := Read("fixtures/blockscalars.env")
+	if err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"NAME":        "example",
+		"SCRIPT":      "echo \"hello\"\n  indented more\n\necho \"done\"\n",
+		"DESCRIPTION": "this is folded into one line\nand a new paragraph\n",
+		"STRIPPED":    "no final newline",
+		"AFTER":       "still parsed",
+	}
+	if len(envMap) != len(expectedValues) {
+		t.Errorf("Expected %v got %v", expectedValues, envMap)
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Mismatch for key '%v': expected %q got %q", key, value, envMap[key])
+		}
+	}
+}
+
+func TestYAMLBlockScalarInconsistentIndentation(t *testing.T) {
+	_, err := Read("fixtures/badblockscalar.env")
+	if err == nil || !strings.Contains(err.Error(), "line 1: inconsistent indentation in block on line 3") {
+		t.Errorf("Expected an indentation error, got %v", err)
+	}
+}
+
+func TestYAMLBlockScalarNeedsColon(t *testing.T) {
+	envMap, err := Parse(strings.NewReader("PIPE=|\nARROW=>\n"))
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	if envMap["PIPE"] != "|" || envMap["ARROW"] != ">" {
+		t.Errorf("Expected = assignments to be left alone, got %v", envMap)
+	}
+}